@@ -0,0 +1,83 @@
+// Команда rollup пересобирает агрегаты расходов и сверяет их с расчетом
+// по подпискам.
+//
+//	go run ./cmd/rollup          # пересборка и сверка
+//	go run ./cmd/rollup -check   # только сверка
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"subscribe_project/internal/config"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	checkOnly := flag.Bool("check", false, "only compare rollups with subscriptions, without rebuilding")
+	flag.Parse()
+
+	envErr := config.LoadEnv()
+	logger.InitLogger("subscription-rollup")
+	if envErr != nil {
+		logger.Log.WithField("error", envErr).Warn(".env file not found, using environment variables")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to load configuration")
+	}
+
+	db, err := sqlx.Connect("postgres", cfg.GetDBConnectionString())
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to connect to database")
+	}
+	defer db.Close()
+
+	svc := services.NewRollupService(repository.NewRollupRepository(db))
+	ctx := context.Background()
+
+	if !*checkOnly {
+		if err := svc.Rebuild(ctx); err != nil {
+			logger.Log.WithError(err).Fatal("Failed to rebuild spend rollups")
+		}
+	}
+
+	mismatches, err := svc.Check(ctx)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to check spend rollups")
+	}
+
+	for _, m := range mismatches {
+		logger.Log.WithFields(logrus.Fields{
+			"month":        m.Month.Format("2006-01"),
+			"user_id":      m.UserID.String(),
+			"service_name": m.ServiceName,
+			"rollup_share": valueOf(m.RollupShare),
+			"raw_share":    valueOf(m.RawShare),
+			"rollup_owned": valueOf(m.RollupOwned),
+			"raw_owned":    valueOf(m.RawOwned),
+		}).Error("Spend rollup does not match subscriptions")
+	}
+
+	if len(mismatches) > 0 {
+		logger.Log.WithField("mismatches", len(mismatches)).Error("Spend rollups are inconsistent")
+		os.Exit(1)
+	}
+
+	logger.Log.Info("Spend rollups are consistent")
+}
+
+// valueOf разыменовывает значение для лога; nil — строки нет с этой стороны.
+func valueOf[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"subscribe_project/internal/cache"
 	"subscribe_project/internal/config"
 	"subscribe_project/internal/handlers"
+	"subscribe_project/internal/metrics"
 	"subscribe_project/internal/middleware"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/notify"
+	"subscribe_project/internal/ratelimit"
 	"subscribe_project/internal/repository"
 	"subscribe_project/internal/services"
+	"subscribe_project/internal/tracing"
 	"subscribe_project/pkg/logger"
+	"time"
+	// База часовых поясов встроена в бинарник: в alpine-образе нет tzdata.
+	_ "time/tzdata"
 
 	_ "subscribe_project/docs"
 
@@ -15,6 +25,7 @@ import (
 	"github.com/gofiber/swagger"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -41,7 +52,11 @@ import (
 // @accept json
 // @produce json
 func main() {
+	envErr := config.LoadEnv()
 	logger.InitLogger("subscription-service")
+	if envErr != nil {
+		logger.Log.WithField("error", envErr).Warn(".env file not found, using environment variables")
+	}
 
 	logger.Log.Info("Starting subscription service...")
 
@@ -50,6 +65,24 @@ func main() {
 		logger.Log.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:       cfg.TracingExporter,
+		OTLPEndpoint:   cfg.TracingOTLPEndpoint,
+		SampleRatio:    cfg.TracingSampleRatio,
+		ServiceName:    "subscription-service",
+		ServiceVersion: cfg.ServiceVersion,
+		Environment:    cfg.Environment,
+	})
+	if err != nil {
+		logger.Log.WithError(err).Fatal("Failed to initialize tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Log.WithError(err).Error("Failed to flush traces")
+		}
+	}()
+	logger.Log.WithField("exporter", cfg.TracingExporter).Info("Tracing initialized")
+
 	logger.Log.WithField("db", cfg.DBName).Info("Connecting to database...")
 	db, err := sqlx.Connect("postgres", cfg.GetDBConnectionString())
 	if err != nil {
@@ -59,18 +92,109 @@ func main() {
 
 	logger.Log.Info("Database connection established")
 
+	metrics.RegisterDBStats(db.DB, cfg.DBName)
+
 	repo := repository.NewSubscriptionRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	catalogRepo := repository.NewCatalogRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(db)
+	memberRepo := repository.NewMemberRepository(db)
+	phaseRepo := repository.NewPhaseRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	pauseRepo := repository.NewPauseRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	budgetRepo := repository.NewBudgetRepository(db)
+	forecastRepo := repository.NewForecastRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	rollupRepo := repository.NewRollupRepository(db)
+	duplicateRepo := repository.NewDuplicateRepository(db)
+	timelineRepo := repository.NewTimelineRepository(db)
+	priceInsightRepo := repository.NewPriceInsightRepository(db)
+	recommendationRepo := repository.NewRecommendationRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
 	logger.Log.Info("Repository initialized")
 
-	svc := services.NewSubscriptionService(repo)
+	switch cfg.DuplicateStrictness {
+	case models.DuplicatesOff, models.DuplicatesWarn, models.DuplicatesReject:
+	default:
+		logger.Log.WithField("strictness", cfg.DuplicateStrictness).Fatal("Unknown duplicate strictness")
+	}
+	svc := services.NewSubscriptionService(repo, catalogRepo, categoryRepo, userRepo, paymentMethodRepo, duplicateRepo, usageRepo, cfg.DuplicateStrictness)
+	var readCache cache.Cache
+	switch cfg.CacheBackend {
+	case "memory":
+		readCache = cache.NewLRU(cfg.CacheSize)
+	case "redis":
+		options, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("Invalid Redis URL")
+		}
+		redisClient := redis.NewClient(options)
+		defer redisClient.Close()
+		readCache = cache.NewRedis(redisClient, "subscription-service:")
+	case "none":
+	default:
+		logger.Log.WithField("backend", cfg.CacheBackend).Fatal("Unknown cache backend")
+	}
+	invalidator := services.NewNoopCacheInvalidator()
+	if readCache != nil {
+		svc = services.NewCachedSubscriptionService(svc, readCache, memberRepo, cfg.CacheTTL)
+		invalidator = services.NewCacheInvalidator(readCache, memberRepo)
+	}
+	catalogSvc := services.NewCatalogService(catalogRepo, invalidator)
+	categorySvc := services.NewCategoryService(categoryRepo, invalidator)
+	tagSvc := services.NewTagService(tagRepo, invalidator)
+	userSvc := services.NewUserService(userRepo, invalidator)
+	memberSvc := services.NewMemberService(memberRepo, repo, userRepo, invalidator)
+	phaseSvc := services.NewPhaseService(phaseRepo, repo, invalidator)
+	eventSvc := services.NewEventService(eventRepo)
+	pauseSvc := services.NewPauseService(pauseRepo, repo, invalidator)
+	paymentSvc := services.NewPaymentService(paymentRepo)
+	paymentMethodSvc := services.NewPaymentMethodService(paymentMethodRepo, userRepo, invalidator)
+
+	notifier := notify.Multi{notify.LogNotifier{}}
+	if cfg.AlertWebhookURL != "" {
+		notifier = append(notifier, notify.NewWebhookNotifier(cfg.AlertWebhookURL, 10*time.Second))
+	}
+	budgetSvc := services.NewBudgetService(budgetRepo, repo, catalogRepo, categoryRepo, userRepo, notifier)
+	forecastSvc := services.NewForecastService(forecastRepo, catalogRepo)
+	analyticsSvc := services.NewAnalyticsService(analyticsRepo, catalogRepo)
+	rollupSvc := services.NewRollupService(rollupRepo)
+	timelineSvc := services.NewTimelineService(timelineRepo, userRepo)
+	if cfg.PriceIncreaseThreshold <= 0 {
+		logger.Log.WithField("threshold", cfg.PriceIncreaseThreshold).Fatal("Price increase threshold must be positive")
+	}
+	priceInsightSvc := services.NewPriceInsightService(priceInsightRepo, cfg.PriceIncreaseThreshold)
+	recommendationSvc := services.NewRecommendationService(recommendationRepo, userRepo)
 	logger.Log.Info("Service initialized")
 
-	handler := handlers.NewSubscriptionHandler(svc)
+	routeHandlers := appHandlers{
+		subscriptions: handlers.NewSubscriptionHandler(svc),
+		catalog:       handlers.NewCatalogHandler(catalogSvc),
+		categories:    handlers.NewCategoryHandler(categorySvc),
+		tags:          handlers.NewTagHandler(tagSvc),
+		users:         handlers.NewUserHandler(userSvc),
+		members:       handlers.NewMemberHandler(memberSvc),
+		phases:        handlers.NewPhaseHandler(phaseSvc),
+		events:        handlers.NewEventHandler(eventSvc),
+		pauses:        handlers.NewPauseHandler(pauseSvc),
+		payments:      handlers.NewPaymentHandler(paymentSvc),
+		methods:       handlers.NewPaymentMethodHandler(paymentMethodSvc),
+		budgets:       handlers.NewBudgetHandler(budgetSvc),
+		forecast:      handlers.NewForecastHandler(forecastSvc),
+		analytics:     handlers.NewAnalyticsHandler(analyticsSvc),
+		timeline:      handlers.NewTimelineHandler(timelineSvc),
+		priceInsights: handlers.NewPriceInsightHandler(priceInsightSvc),
+		recommend:     handlers.NewRecommendationHandler(recommendationSvc),
+	}
 	logger.Log.Info("Handlers initialized")
 
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			logger.Log.WithFields(logrus.Fields{
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 				"error":  err.Error(),
 				"method": c.Method(),
 				"path":   c.Path(),
@@ -83,10 +207,48 @@ func main() {
 		},
 	})
 
+	app.Use(middleware.MetricsMiddleware())
+	app.Use(middleware.TracingMiddleware())
+	app.Use(middleware.RequestIDMiddleware())
 	app.Use(middleware.LoggerMiddleware())
 	logger.Log.Info("Middleware registered")
 
-	setupRoutes(app, handler)
+	go cleanupIdempotencyKeys(idempotencyRepo, time.Hour)
+	go metrics.StartBusinessMetricsRefresher(context.Background(), repo, cfg.MetricsRefreshInterval)
+	go checkBudgets(budgetSvc, cfg.BudgetCheckInterval)
+	go refreshRollups(rollupSvc, cfg.RollupRefreshInterval)
+	go refreshPriceInsights(priceInsightSvc, cfg.PriceInsightsInterval)
+
+	var apiMiddleware []fiber.Handler
+	if cfg.RateLimitEnabled {
+		policies, err := ratelimit.ParsePolicies(cfg.RateLimitDefault, cfg.RateLimitRoutes)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("Invalid rate limit configuration")
+		}
+
+		var store ratelimit.Store
+		switch cfg.RateLimitStore {
+		case "postgres":
+			postgresStore := ratelimit.NewPostgresStore(db)
+			go cleanupRateLimitBuckets(postgresStore, time.Hour)
+			store = postgresStore
+		case "memory":
+			store = ratelimit.NewMemoryStore()
+		default:
+			logger.Log.WithField("store", cfg.RateLimitStore).Fatal("Unknown rate limit store")
+		}
+
+		apiMiddleware = append(apiMiddleware, middleware.RateLimitMiddleware(store, policies))
+		logger.Log.WithFields(logrus.Fields{
+			"store":  cfg.RateLimitStore,
+			"routes": len(policies.Routes),
+		}).Info("Rate limiting enabled")
+	}
+
+	// Идемпотентность проверяется после лимитов, чтобы ответ 429 не сохранялся для ключа.
+	apiMiddleware = append(apiMiddleware, middleware.IdempotencyMiddleware(idempotencyRepo, cfg.IdempotencyTTL))
+
+	setupRoutes(app, routeHandlers, apiMiddleware...)
 	logger.Log.WithField("port", cfg.ServerPort).Info("Routes registered")
 
 	logger.Log.WithField("port", cfg.ServerPort).Info("Starting server...")
@@ -96,29 +258,217 @@ func main() {
 	}
 }
 
-func setupRoutes(app *fiber.App, handler *handlers.SubscriptionHandler) {
+func cleanupIdempotencyKeys(repo repository.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := repo.DeleteExpired(context.Background())
+		if err != nil {
+			logger.Log.WithError(err).Error("Failed to delete expired idempotency keys")
+			continue
+		}
+		logger.Log.WithField("deleted", deleted).Debug("Expired idempotency keys deleted")
+	}
+}
+
+func cleanupRateLimitBuckets(store *ratelimit.PostgresStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := store.DeleteIdle(context.Background(), 24*time.Hour)
+		if err != nil {
+			logger.Log.WithError(err).Error("Failed to delete idle rate limit buckets")
+			continue
+		}
+		logger.Log.WithField("deleted", deleted).Debug("Idle rate limit buckets deleted")
+	}
+}
+
+func checkBudgets(svc services.BudgetService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		fired, err := svc.CheckBudgets(context.Background())
+		if err != nil {
+			logger.Log.WithError(err).Error("Failed to check budgets")
+			continue
+		}
+		logger.Log.WithField("alerts", fired).Debug("Budgets checked")
+	}
+}
+
+// refreshRollups строит агрегаты сразу при старте, затем пересчитывает
+// устаревшие по таймеру.
+func refreshRollups(svc services.RollupService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		refreshed, rebuilt, err := svc.Refresh(context.Background())
+		if err != nil {
+			logger.Log.WithError(err).Error("Failed to refresh spend rollups")
+			continue
+		}
+		logger.Log.WithFields(logrus.Fields{
+			"users":   refreshed,
+			"rebuilt": rebuilt,
+		}).Debug("Spend rollups refreshed")
+	}
+}
+
+// refreshPriceInsights пересчитывает аномалии цен сразу при старте, затем
+// раз в interval.
+func refreshPriceInsights(svc services.PriceInsightService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		if _, err := svc.Refresh(context.Background()); err != nil {
+			logger.Log.WithError(err).Error("Failed to refresh price insights")
+		}
+	}
+}
+
+type appHandlers struct {
+	subscriptions *handlers.SubscriptionHandler
+	catalog       *handlers.CatalogHandler
+	categories    *handlers.CategoryHandler
+	tags          *handlers.TagHandler
+	users         *handlers.UserHandler
+	members       *handlers.MemberHandler
+	phases        *handlers.PhaseHandler
+	events        *handlers.EventHandler
+	pauses        *handlers.PauseHandler
+	payments      *handlers.PaymentHandler
+	methods       *handlers.PaymentMethodHandler
+	budgets       *handlers.BudgetHandler
+	forecast      *handlers.ForecastHandler
+	analytics     *handlers.AnalyticsHandler
+	timeline      *handlers.TimelineHandler
+	priceInsights *handlers.PriceInsightHandler
+	recommend     *handlers.RecommendationHandler
+}
+
+func setupRoutes(app *fiber.App, h appHandlers, apiMiddleware ...fiber.Handler) {
 	logger.Log.Info("Setting up routes...")
 
-	api := app.Group("/api")
+	api := app.Group("/api", apiMiddleware...)
 	app.Get("/swagger/*", swagger.HandlerDefault)
 	app.Get("/swagger/doc.json", func(c *fiber.Ctx) error {
 		return c.SendFile("./docs/swagger.json")
 	})
 	logger.Log.Info("Swagger UI registered at /swagger/index.html")
 
-	api.Post("/subscriptions", handler.CreateSubscription)
+	api.Post("/subscriptions", h.subscriptions.CreateSubscription)
 	logger.Log.Info("Registered POST /api/subscriptions")
 
-	api.Get("/subscriptions/:id", handler.GetSubscription)
+	api.Get("/subscriptions/:id", h.subscriptions.GetSubscription)
 	logger.Log.Info("Registered GET /api/subscriptions/:id")
 
-	api.Put("/subscriptions/:id", handler.UpdateSubscription)
-	api.Delete("/subscriptions/:id", handler.DeleteSubscription)
-	api.Get("/subscriptions", handler.ListSubscriptions)
-	api.Post("/summary", handler.GetSummary)
+	api.Put("/subscriptions/:id", h.subscriptions.UpdateSubscription)
+	api.Delete("/subscriptions/:id", h.subscriptions.DeleteSubscription)
+	api.Get("/subscriptions", h.subscriptions.ListSubscriptions)
+	api.Post("/summary", h.subscriptions.GetSummary)
+
+	api.Post("/services", h.catalog.CreateService)
+	api.Get("/services", h.catalog.ListServices)
+	api.Get("/services/:id", h.catalog.GetService)
+	api.Put("/services/:id", h.catalog.UpdateService)
+	api.Delete("/services/:id", h.catalog.DeleteService)
+	logger.Log.Info("Registered /api/services catalog routes")
+
+	api.Post("/categories", h.categories.CreateCategory)
+	api.Get("/categories", h.categories.ListCategories)
+	api.Get("/categories/:id", h.categories.GetCategory)
+	api.Put("/categories/:id", h.categories.UpdateCategory)
+	api.Delete("/categories/:id", h.categories.DeleteCategory)
+
+	api.Post("/tags", h.tags.CreateTag)
+	api.Get("/tags", h.tags.ListTags)
+	api.Delete("/tags/:id", h.tags.DeleteTag)
+	api.Put("/subscriptions/:id/tags", h.tags.SetSubscriptionTags)
+	logger.Log.Info("Registered /api/categories and /api/tags routes")
+
+	api.Post("/users", h.users.CreateUser)
+	api.Get("/users", h.users.ListUsers)
+	api.Get("/users/:id", h.users.GetUser)
+	api.Put("/users/:id", h.users.UpdateUser)
+	api.Delete("/users/:id", h.users.DeleteUser)
+	api.Get("/users/:id/timeline", h.timeline.GetTimeline)
+	api.Get("/users/:id/recommendations", h.recommend.GetRecommendations)
+	logger.Log.Info("Registered /api/users routes")
+
+	api.Get("/subscriptions/:id/members", h.members.GetMembers)
+	api.Put("/subscriptions/:id/members", h.members.SetMembers)
+	api.Delete("/subscriptions/:id/members/:user_id", h.members.RemoveMember)
+	logger.Log.Info("Registered subscription member routes")
+
+	api.Get("/subscriptions/:id/phases", h.phases.GetPhases)
+	api.Put("/subscriptions/:id/phases", h.phases.SetPhases)
+	api.Get("/events/upcoming", h.events.ListUpcomingEvents)
+	logger.Log.Info("Registered subscription phase and event routes")
+
+	api.Post("/subscriptions/:id/pause", h.pauses.PauseSubscription)
+	api.Post("/subscriptions/:id/resume", h.pauses.ResumeSubscription)
+	api.Get("/subscriptions/:id/pauses", h.pauses.ListPauses)
+	logger.Log.Info("Registered subscription pause routes")
+
+	api.Post("/subscriptions/:id/cancel", h.subscriptions.CancelSubscription)
+	api.Post("/subscriptions/:id/cancel-at-period-end", h.subscriptions.CancelAtPeriodEnd)
+	api.Post("/subscriptions/:id/reactivate", h.subscriptions.ReactivateSubscription)
+	api.Get("/subscriptions/:id/transitions", h.subscriptions.ListTransitions)
+	api.Get("/subscriptions/:id/merges", h.subscriptions.ListMerges)
+	api.Post("/subscriptions/:id/usage", h.subscriptions.RecordUsage)
+	api.Get("/subscriptions/:id/usage", h.subscriptions.GetUsage)
+	logger.Log.Info("Registered subscription lifecycle routes")
+
+	api.Get("/duplicates", h.subscriptions.ListDuplicates)
+	api.Post("/duplicates/merge", h.subscriptions.MergeSubscriptions)
+	logger.Log.Info("Registered duplicate routes")
+
+	api.Post("/payments", h.payments.CreatePayment)
+	api.Get("/payments", h.payments.ListPayments)
+	api.Get("/payments/reconciliation", h.payments.Reconcile)
+	api.Get("/payments/:id", h.payments.GetPayment)
+	api.Delete("/payments/:id", h.payments.DeletePayment)
+	logger.Log.Info("Registered /api/payments routes")
+
+	api.Post("/users/:id/payment-methods", h.methods.CreatePaymentMethod)
+	api.Get("/users/:id/payment-methods", h.methods.ListUserPaymentMethods)
+	api.Get("/payment-methods/expiring", h.methods.ListExpiring)
+	api.Get("/payment-methods/:id", h.methods.GetPaymentMethod)
+	api.Put("/payment-methods/:id", h.methods.UpdatePaymentMethod)
+	api.Delete("/payment-methods/:id", h.methods.DeletePaymentMethod)
+	logger.Log.Info("Registered payment method routes")
+
+	api.Post("/budgets", h.budgets.CreateBudget)
+	api.Get("/budgets", h.budgets.ListBudgets)
+	api.Get("/budgets/status", h.budgets.ListBudgetStatuses)
+	api.Get("/budgets/:id", h.budgets.GetBudget)
+	api.Put("/budgets/:id", h.budgets.UpdateBudget)
+	api.Delete("/budgets/:id", h.budgets.DeleteBudget)
+	api.Get("/budgets/:id/status", h.budgets.GetBudgetStatus)
+	logger.Log.Info("Registered /api/budgets routes")
+
+	api.Get("/forecast", h.forecast.GetForecast)
+	logger.Log.Info("Registered GET /api/forecast")
+
+	api.Get("/analytics/mrr", h.analytics.GetMRR)
+	api.Get("/analytics/subscriptions", h.analytics.GetSubscriptionFlow)
+	api.Get("/analytics/churn", h.analytics.GetChurn)
+	logger.Log.Info("Registered /api/analytics routes")
+
+	api.Get("/insights/prices", h.priceInsights.GetPriceInsights)
+	logger.Log.Info("Registered /api/insights routes")
+
+	app.Get("/metrics", metrics.Handler())
+	logger.Log.Info("Metrics registered at /metrics")
 
 	app.Get("/health", func(c *fiber.Ctx) error {
-		logger.Log.Debug("Health check requested")
+		logger.FromContext(c.UserContext()).Debug("Health check requested")
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
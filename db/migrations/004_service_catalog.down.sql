@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS service_id;
+DROP TABLE IF EXISTS services;
\ No newline at end of file
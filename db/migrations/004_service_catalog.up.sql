@@ -0,0 +1,31 @@
+CREATE TABLE services (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    category VARCHAR(100),
+    website VARCHAR(255),
+    default_price INTEGER CHECK (default_price >= 0),
+    logo_url VARCHAR(255),
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_services_name ON services(LOWER(name));
+CREATE INDEX idx_services_aliases ON services USING GIN (aliases);
+
+ALTER TABLE subscriptions ADD COLUMN service_id UUID REFERENCES services(id) ON DELETE SET NULL;
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
+
+-- Алиасы хранятся в нормализованном виде: нижний регистр, схлопнутые пробелы.
+-- Каноническим именем становится самый частый вариант написания.
+INSERT INTO services (name, aliases)
+SELECT MODE() WITHIN GROUP (ORDER BY TRIM(service_name)),
+       ARRAY_AGG(DISTINCT LOWER(REGEXP_REPLACE(TRIM(service_name), '\s+', ' ', 'g')))
+FROM subscriptions
+GROUP BY LOWER(REGEXP_REPLACE(TRIM(service_name), '\s+', ' ', 'g'));
+
+UPDATE subscriptions s
+SET service_id = sv.id,
+    service_name = sv.name
+FROM services sv
+WHERE LOWER(REGEXP_REPLACE(TRIM(s.service_name), '\s+', ' ', 'g')) = ANY(sv.aliases);
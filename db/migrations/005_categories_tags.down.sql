@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS subscription_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
\ No newline at end of file
@@ -0,0 +1,45 @@
+CREATE TABLE categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    parent_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tags_name ON tags(LOWER(name));
+
+CREATE TABLE subscription_tags (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, tag_id)
+);
+
+CREATE INDEX idx_subscription_tags_tag_id ON subscription_tags(tag_id);
+
+ALTER TABLE subscriptions ADD COLUMN category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX idx_subscriptions_category_id ON subscriptions(category_id);
+
+INSERT INTO categories (name, slug) VALUES
+    ('Streaming', 'streaming'),
+    ('Music', 'music'),
+    ('Dev tools', 'dev-tools'),
+    ('Cloud', 'cloud'),
+    ('Productivity', 'productivity'),
+    ('Education', 'education');
+
+-- Подписки получают категорию из каталога сервисов, если она совпадает по имени или slug.
+UPDATE subscriptions s
+SET category_id = c.id
+FROM services sv, categories c
+WHERE s.service_id = sv.id
+  AND s.category_id IS NULL
+  AND (LOWER(sv.category) = LOWER(c.name) OR LOWER(sv.category) = c.slug);
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS subscriptions DROP CONSTRAINT IF EXISTS fk_subscriptions_user_id;
+DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    preferences JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) WITHOUT TIME ZONE
+);
+
+-- Email освобождается после мягкого удаления пользователя.
+CREATE UNIQUE INDEX idx_users_email ON users(LOWER(email)) WHERE deleted_at IS NULL;
+
+-- Для уже существующих подписок создаются пользователи-заглушки, чтобы можно было добавить внешний ключ.
+INSERT INTO users (id, name)
+SELECT DISTINCT user_id, 'User ' || LEFT(user_id::TEXT, 8)
+FROM subscriptions;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT fk_subscriptions_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS subscription_shares;
+DROP TABLE IF EXISTS subscription_members;
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS split_rule;
\ No newline at end of file
@@ -0,0 +1,59 @@
+ALTER TABLE subscriptions
+    ADD COLUMN split_rule VARCHAR(20) NOT NULL DEFAULT 'equal'
+    CHECK (split_rule IN ('equal', 'percentage', 'fixed'));
+
+-- Владелец подписки (subscriptions.user_id) в таблицу участников не входит:
+-- ему достается остаток стоимости после долей участников.
+CREATE TABLE subscription_members (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    share_percent NUMERIC(5, 2) CHECK (share_percent > 0 AND share_percent <= 100),
+    share_amount INTEGER CHECK (share_amount > 0),
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subscription_id, user_id)
+);
+
+CREATE INDEX idx_subscription_members_user_id ON subscription_members(user_id);
+
+-- Доля каждого пользователя в стоимости подписки. Сумма долей по подписке
+-- всегда равна 1: если доли участников превышают цену или 100%, они
+-- пропорционально уменьшаются, а владелец получает ноль.
+CREATE VIEW subscription_shares AS
+WITH totals AS (
+    SELECT s.id AS subscription_id,
+           s.user_id AS owner_id,
+           s.price,
+           s.split_rule,
+           COUNT(m.user_id) AS members,
+           COALESCE(SUM(m.share_percent), 0) AS percent_total,
+           COALESCE(SUM(m.share_amount), 0) AS amount_total
+    FROM subscriptions s
+    LEFT JOIN subscription_members m ON m.subscription_id = s.id
+    GROUP BY s.id
+)
+SELECT t.subscription_id,
+       t.owner_id AS user_id,
+       'owner' AS role,
+       CASE t.split_rule
+           WHEN 'percentage' THEN GREATEST(100 - t.percent_total, 0) / 100.0
+           WHEN 'fixed' THEN CASE
+               WHEN t.price = 0 THEN 1.0
+               ELSE GREATEST(t.price - t.amount_total, 0)::NUMERIC / t.price
+           END
+           ELSE 1.0 / (t.members + 1)
+       END AS share_ratio
+FROM totals t
+UNION ALL
+SELECT m.subscription_id,
+       m.user_id,
+       'member' AS role,
+       CASE t.split_rule
+           WHEN 'percentage' THEN COALESCE(m.share_percent, 0) / GREATEST(100, t.percent_total)
+           WHEN 'fixed' THEN CASE
+               WHEN t.price = 0 THEN 0
+               ELSE COALESCE(m.share_amount, 0)::NUMERIC / GREATEST(t.price, t.amount_total)
+           END
+           ELSE 1.0 / (t.members + 1)
+       END AS share_ratio
+FROM subscription_members m
+JOIN totals t ON t.subscription_id = m.subscription_id;
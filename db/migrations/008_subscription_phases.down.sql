@@ -0,0 +1,3 @@
+DROP FUNCTION IF EXISTS subscription_monthly_charges(DATE, DATE);
+DROP VIEW IF EXISTS subscription_phase_periods;
+DROP TABLE IF EXISTS subscription_phases;
\ No newline at end of file
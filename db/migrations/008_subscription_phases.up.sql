@@ -0,0 +1,44 @@
+-- Начальные фазы подписки (пробный период, вводная цена) идут подряд от
+-- start_date в порядке sequence. После последней фазы действует обычная цена
+-- subscriptions.price.
+CREATE TABLE subscription_phases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    phase_type VARCHAR(20) NOT NULL CHECK (phase_type IN ('trial', 'intro')),
+    duration_months INTEGER NOT NULL CHECK (duration_months > 0),
+    price INTEGER NOT NULL CHECK (price >= 0),
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, sequence)
+);
+
+-- Календарные границы фаз; end_date не включается (первый месяц следующей фазы).
+CREATE VIEW subscription_phase_periods AS
+SELECT p.id,
+       p.subscription_id,
+       p.sequence,
+       p.phase_type,
+       p.duration_months,
+       p.price,
+       (s.start_date + MAKE_INTERVAL(months => (SUM(p.duration_months) OVER w - p.duration_months)::INTEGER))::DATE AS start_date,
+       (s.start_date + MAKE_INTERVAL(months => (SUM(p.duration_months) OVER w)::INTEGER))::DATE AS end_date
+FROM subscription_phases p
+JOIN subscriptions s ON s.id = p.subscription_id
+WINDOW w AS (PARTITION BY p.subscription_id ORDER BY p.sequence);
+
+-- Помесячные начисления по подпискам за период [period_start, period_end]
+-- (границы — первые числа месяцев, включительно). Цена месяца берется из
+-- активной фазы, иначе из subscriptions.price.
+CREATE FUNCTION subscription_monthly_charges(period_start DATE, period_end DATE)
+RETURNS TABLE (subscription_id UUID, charge_month DATE, amount INTEGER)
+LANGUAGE sql STABLE AS $$
+    SELECT s.id, m.month::DATE, COALESCE(p.price, s.price)
+    FROM subscriptions s
+    CROSS JOIN LATERAL GENERATE_SERIES(
+        GREATEST(s.start_date, period_start),
+        LEAST(COALESCE(s.end_date, period_end), period_end),
+        INTERVAL '1 month'
+    ) AS m(month)
+    LEFT JOIN subscription_phase_periods p
+           ON p.subscription_id = s.id AND m.month >= p.start_date AND m.month < p.end_date
+$$;
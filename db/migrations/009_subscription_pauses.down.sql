@@ -0,0 +1,15 @@
+CREATE OR REPLACE FUNCTION subscription_monthly_charges(period_start DATE, period_end DATE)
+RETURNS TABLE (subscription_id UUID, charge_month DATE, amount INTEGER)
+LANGUAGE sql STABLE AS $$
+    SELECT s.id, m.month::DATE, COALESCE(p.price, s.price)
+    FROM subscriptions s
+    CROSS JOIN LATERAL GENERATE_SERIES(
+        GREATEST(s.start_date, period_start),
+        LEAST(COALESCE(s.end_date, period_end), period_end),
+        INTERVAL '1 month'
+    ) AS m(month)
+    LEFT JOIN subscription_phase_periods p
+           ON p.subscription_id = s.id AND m.month >= p.start_date AND m.month < p.end_date
+$$;
+
+DROP TABLE IF EXISTS subscription_pauses;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- Интервалы паузы в месяцах: start_date включается, end_date (месяц
+-- возобновления) не включается. Пустой end_date — пауза без даты возобновления.
+CREATE TABLE subscription_pauses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE CHECK (end_date > start_date),
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_pauses_subscription_id ON subscription_pauses(subscription_id, start_date);
+
+-- Месяцы на паузе не начисляются.
+CREATE OR REPLACE FUNCTION subscription_monthly_charges(period_start DATE, period_end DATE)
+RETURNS TABLE (subscription_id UUID, charge_month DATE, amount INTEGER)
+LANGUAGE sql STABLE AS $$
+    SELECT s.id, m.month::DATE, COALESCE(p.price, s.price)
+    FROM subscriptions s
+    CROSS JOIN LATERAL GENERATE_SERIES(
+        GREATEST(s.start_date, period_start),
+        LEAST(COALESCE(s.end_date, period_end), period_end),
+        INTERVAL '1 month'
+    ) AS m(month)
+    LEFT JOIN subscription_phase_periods p
+           ON p.subscription_id = s.id AND m.month >= p.start_date AND m.month < p.end_date
+    WHERE NOT EXISTS (
+        SELECT 1 FROM subscription_pauses sp
+        WHERE sp.subscription_id = s.id
+          AND m.month >= sp.start_date
+          AND (sp.end_date IS NULL OR m.month < sp.end_date)
+    )
+$$;
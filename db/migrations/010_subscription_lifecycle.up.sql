@@ -0,0 +1,20 @@
+-- Явное состояние жизненного цикла. Хранятся только состояния, задаваемые
+-- переходами; trialing, paused и expired вычисляются по фазам, паузам и end_date.
+ALTER TABLE subscriptions
+    ADD COLUMN state VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (state IN ('active', 'cancel_scheduled', 'cancelled')),
+    ADD COLUMN status_changed_at TIMESTAMP(0) WITHOUT TIME ZONE,
+    ADD COLUMN cancelled_at TIMESTAMP(0) WITHOUT TIME ZONE;
+
+CREATE INDEX idx_subscriptions_state ON subscriptions(state);
+
+-- История переходов между статусами.
+CREATE TABLE subscription_transitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_transitions_subscription_id ON subscription_transitions(subscription_id, created_at);
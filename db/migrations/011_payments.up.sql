@@ -0,0 +1,14 @@
+-- Фактические списания по подпискам.
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    paid_at DATE NOT NULL,
+    payment_method VARCHAR(100),
+    reference VARCHAR(255),
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payments_subscription_id ON payments(subscription_id, paid_at);
+CREATE INDEX idx_payments_paid_at ON payments(paid_at);
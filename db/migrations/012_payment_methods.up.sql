@@ -0,0 +1,19 @@
+-- Способы оплаты пользователя. label хранится только в маскированном виде
+-- (например, "Visa •••• 4242"); expiry_month — первое число последнего месяца
+-- действия карты.
+CREATE TABLE payment_methods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('card', 'bank_account', 'wallet')),
+    label VARCHAR(100) NOT NULL,
+    expiry_month DATE,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_methods_user_id ON payment_methods(user_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_payment_method_id ON subscriptions(payment_method_id);
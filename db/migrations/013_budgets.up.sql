@@ -0,0 +1,29 @@
+-- Бюджеты пользователя на подписки. Бюджет ограничивает долю пользователя в
+-- расходах: все подписки (scope = user), категорию с подкатегориями или сервис.
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('user', 'category', 'service')),
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    service_name VARCHAR(100),
+    period VARCHAR(10) NOT NULL CHECK (period IN ('monthly', 'yearly')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((scope = 'category') = (category_id IS NOT NULL)),
+    CHECK ((scope = 'service') = (service_name IS NOT NULL))
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
+
+-- Отправленные уведомления о порогах: не больше одного на порог за период.
+CREATE TABLE budget_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    threshold INTEGER NOT NULL,
+    spent INTEGER NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (budget_id, period_start, threshold)
+);
@@ -0,0 +1,44 @@
+-- Периодичность оплаты: price — сумма одного списания за весь интервал.
+ALTER TABLE subscriptions
+    ADD COLUMN billing_interval VARCHAR(10) NOT NULL DEFAULT 'monthly'
+        CHECK (billing_interval IN ('monthly', 'quarterly', 'yearly'));
+
+-- Число месяцев в интервале оплаты.
+CREATE FUNCTION billing_interval_months(billing_interval VARCHAR)
+RETURNS INTEGER
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE billing_interval WHEN 'quarterly' THEN 3 WHEN 'yearly' THEN 12 ELSE 1 END
+$$;
+
+-- Месяцы фаз начисляются помесячно по цене фазы. Обычная цена списывается в
+-- первый месяц после фаз (или в start_date) и далее раз в интервал оплаты.
+CREATE OR REPLACE FUNCTION subscription_monthly_charges(period_start DATE, period_end DATE)
+RETURNS TABLE (subscription_id UUID, charge_month DATE, amount INTEGER)
+LANGUAGE sql STABLE AS $$
+    SELECT s.id, m.month::DATE, COALESCE(p.price, s.price)
+    FROM subscriptions s
+    CROSS JOIN LATERAL (
+        SELECT COALESCE(MAX(pp.end_date), s.start_date) AS anchor
+        FROM subscription_phase_periods pp
+        WHERE pp.subscription_id = s.id
+    ) b
+    CROSS JOIN LATERAL GENERATE_SERIES(
+        GREATEST(s.start_date, period_start),
+        LEAST(COALESCE(s.end_date, period_end), period_end),
+        INTERVAL '1 month'
+    ) AS m(month)
+    LEFT JOIN subscription_phase_periods p
+           ON p.subscription_id = s.id AND m.month >= p.start_date AND m.month < p.end_date
+    WHERE (
+        p.id IS NOT NULL
+        OR ((EXTRACT(YEAR FROM m.month) - EXTRACT(YEAR FROM b.anchor)) * 12
+            + EXTRACT(MONTH FROM m.month) - EXTRACT(MONTH FROM b.anchor))::INTEGER
+           % billing_interval_months(s.billing_interval) = 0
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM subscription_pauses sp
+        WHERE sp.subscription_id = s.id
+          AND m.month >= sp.start_date
+          AND (sp.end_date IS NULL OR m.month < sp.end_date)
+    )
+$$;
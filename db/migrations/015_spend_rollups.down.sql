@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS subscription_pauses_rollup_dirty ON subscription_pauses;
+DROP TRIGGER IF EXISTS subscription_phases_rollup_dirty ON subscription_phases;
+DROP TRIGGER IF EXISTS subscription_members_rollup_dirty ON subscription_members;
+DROP TRIGGER IF EXISTS subscriptions_rollup_dirty ON subscriptions;
+
+DROP FUNCTION IF EXISTS subscription_charges_rollup_dirty();
+DROP FUNCTION IF EXISTS subscription_members_rollup_dirty();
+DROP FUNCTION IF EXISTS subscriptions_rollup_dirty();
+DROP FUNCTION IF EXISTS mark_rollup_dirty_user(UUID);
+DROP FUNCTION IF EXISTS mark_rollup_dirty(UUID);
+
+DROP TABLE IF EXISTS spend_rollup_dirty;
+DROP TABLE IF EXISTS spend_rollup_state;
+DROP TABLE IF EXISTS monthly_spend_rollups;
\ No newline at end of file
@@ -0,0 +1,112 @@
+-- Помесячные расходы пользователя по сервису для быстрых сводок.
+-- share_cost — доля пользователя во всех подписках, где он участвует;
+-- owned_cost — полная стоимость подписок, которыми он владеет.
+CREATE TABLE monthly_spend_rollups (
+    month DATE NOT NULL,
+    user_id UUID NOT NULL,
+    service_name VARCHAR(255) NOT NULL,
+    service_id UUID,
+    share_cost NUMERIC NOT NULL,
+    owned_cost INTEGER NOT NULL,
+    subscriptions INTEGER NOT NULL,
+    UNIQUE NULLS NOT DISTINCT (user_id, month, service_name, service_id)
+);
+
+CREATE INDEX idx_monthly_spend_rollups_month ON monthly_spend_rollups(month);
+
+-- Окно месяцев, за которое построены агрегаты. Пока строки нет, сводки
+-- считаются по подпискам.
+CREATE TABLE spend_rollup_state (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    covered_from DATE NOT NULL,
+    covered_to DATE NOT NULL,
+    rebuilt_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    refreshed_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+
+-- Пользователи, чьи агрегаты устарели после изменений. Для них сводки
+-- считаются по подпискам до следующего обновления.
+CREATE TABLE spend_rollup_dirty (
+    user_id UUID PRIMARY KEY,
+    marked_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE FUNCTION mark_rollup_dirty(sub_id UUID)
+RETURNS VOID
+LANGUAGE sql AS $$
+    INSERT INTO spend_rollup_dirty (user_id)
+    SELECT user_id FROM subscription_shares WHERE subscription_id = sub_id
+    ON CONFLICT DO NOTHING
+$$;
+
+CREATE FUNCTION mark_rollup_dirty_user(dirty_user_id UUID)
+RETURNS VOID
+LANGUAGE sql AS $$
+    INSERT INTO spend_rollup_dirty (user_id) VALUES (dirty_user_id)
+    ON CONFLICT DO NOTHING
+$$;
+
+-- Изменение подписки меняет расходы владельца и всех участников.
+CREATE FUNCTION subscriptions_rollup_dirty()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM mark_rollup_dirty_user(OLD.user_id);
+        PERFORM mark_rollup_dirty(OLD.id);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        PERFORM mark_rollup_dirty_user(NEW.user_id);
+        PERFORM mark_rollup_dirty(NEW.id);
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- Участник меняет доли всех пользователей подписки.
+CREATE FUNCTION subscription_members_rollup_dirty()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM mark_rollup_dirty_user(OLD.user_id);
+        PERFORM mark_rollup_dirty(OLD.subscription_id);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        PERFORM mark_rollup_dirty_user(NEW.user_id);
+        PERFORM mark_rollup_dirty(NEW.subscription_id);
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- Фазы и паузы меняют помесячные начисления подписки.
+CREATE FUNCTION subscription_charges_rollup_dirty()
+RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM mark_rollup_dirty(OLD.subscription_id);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        PERFORM mark_rollup_dirty(NEW.subscription_id);
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER subscriptions_rollup_dirty
+AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+FOR EACH ROW EXECUTE FUNCTION subscriptions_rollup_dirty();
+
+CREATE TRIGGER subscription_members_rollup_dirty
+AFTER INSERT OR UPDATE OR DELETE ON subscription_members
+FOR EACH ROW EXECUTE FUNCTION subscription_members_rollup_dirty();
+
+CREATE TRIGGER subscription_phases_rollup_dirty
+AFTER INSERT OR UPDATE OR DELETE ON subscription_phases
+FOR EACH ROW EXECUTE FUNCTION subscription_charges_rollup_dirty();
+
+CREATE TRIGGER subscription_pauses_rollup_dirty
+AFTER INSERT OR UPDATE OR DELETE ON subscription_pauses
+FOR EACH ROW EXECUTE FUNCTION subscription_charges_rollup_dirty();
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS subscription_merges;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- История слияний дубликатов: снимок поглощенной подписки на момент слияния.
+-- Ее платежи и переходы переносятся в оставленную подписку.
+CREATE TABLE subscription_merges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    merged_subscription_id UUID NOT NULL,
+    merged_snapshot JSONB NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_merges_subscription_id ON subscription_merges(subscription_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS price_anomalies;
+DROP TABLE IF EXISTS service_price_stats;
+DROP TABLE IF EXISTS subscription_price_changes;
\ No newline at end of file
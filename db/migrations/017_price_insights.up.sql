@@ -0,0 +1,44 @@
+-- История изменений цены подписок, записываемая при UpdateSubscription.
+CREATE TABLE subscription_price_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    old_price INTEGER NOT NULL,
+    new_price INTEGER NOT NULL,
+    changed_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_price_changes_subscription_id ON subscription_price_changes(subscription_id, changed_at);
+CREATE INDEX idx_subscription_price_changes_changed_at ON subscription_price_changes(changed_at);
+
+-- Распределение месячной цены действующих подписок по сервисам. Пересчитывается
+-- фоновой задачей целиком.
+CREATE TABLE service_price_stats (
+    service_name VARCHAR(100) PRIMARY KEY,
+    subscriptions INTEGER NOT NULL,
+    min_price NUMERIC(12, 2) NOT NULL,
+    p25_price NUMERIC(12, 2) NOT NULL,
+    median_price NUMERIC(12, 2) NOT NULL,
+    p75_price NUMERIC(12, 2) NOT NULL,
+    max_price NUMERIC(12, 2) NOT NULL,
+    computed_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+
+-- Найденные аномалии цен: outlier — подписка заметно дороже, чем у других
+-- пользователей сервиса; increase — резкое повышение цены подписки;
+-- service_increase — повышение цены сразу у нескольких пользователей сервиса.
+CREATE TABLE price_anomalies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('outlier', 'increase', 'service_increase')),
+    service_name VARCHAR(100) NOT NULL,
+    subscription_id UUID REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID,
+    price NUMERIC(12, 2) NOT NULL,
+    baseline NUMERIC(12, 2) NOT NULL,
+    ratio NUMERIC(8, 2) NOT NULL,
+    affected INTEGER NOT NULL DEFAULT 1,
+    observed_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    detected_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_price_anomalies_service_name ON price_anomalies(LOWER(service_name));
+CREATE INDEX idx_price_anomalies_user_id ON price_anomalies(user_id);
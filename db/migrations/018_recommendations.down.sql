@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS unused;
+ALTER TABLE services DROP COLUMN IF EXISTS yearly_price;
\ No newline at end of file
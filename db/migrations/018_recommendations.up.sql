@@ -0,0 +1,5 @@
+-- Цена годового плана сервиса: по ней рекомендуется переход с помесячной оплаты.
+ALTER TABLE services ADD COLUMN yearly_price INTEGER CHECK (yearly_price >= 0);
+
+-- Отметка пользователя о том, что подпиской он не пользуется.
+ALTER TABLE subscriptions ADD COLUMN unused BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subscription_usage_monthly;
+DROP TABLE IF EXISTS subscription_usage;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- Использование подписок: время последнего использования и число использований
+-- по месяцам. Хранится отдельно от subscriptions, чтобы частые события не
+-- помечали агрегаты расходов устаревшими.
+CREATE TABLE subscription_usage (
+    subscription_id UUID PRIMARY KEY REFERENCES subscriptions(id) ON DELETE CASCADE,
+    last_used_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    total_uses INTEGER NOT NULL CHECK (total_uses >= 0)
+);
+
+CREATE TABLE subscription_usage_monthly (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    uses INTEGER NOT NULL CHECK (uses >= 0),
+    PRIMARY KEY (subscription_id, month)
+);
+
+CREATE INDEX idx_subscription_usage_monthly_month ON subscription_usage_monthly(month);
@@ -0,0 +1,3 @@
+ALTER TABLE subscription_price_changes
+    DROP COLUMN IF EXISTS new_billing_interval,
+    DROP COLUMN IF EXISTS old_billing_interval;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Интервал оплаты до и после изменения: повышение цены ищется по ценам,
+-- приведенным к месяцу, чтобы переход с monthly на yearly не считался ростом.
+-- Для старых записей интервал неизвестен и берется текущий интервал подписки.
+ALTER TABLE subscription_price_changes
+    ADD COLUMN old_billing_interval VARCHAR(10) NOT NULL DEFAULT 'monthly'
+        CHECK (old_billing_interval IN ('monthly', 'quarterly', 'yearly')),
+    ADD COLUMN new_billing_interval VARCHAR(10) NOT NULL DEFAULT 'monthly'
+        CHECK (new_billing_interval IN ('monthly', 'quarterly', 'yearly'));
+
+UPDATE subscription_price_changes pc
+SET old_billing_interval = s.billing_interval,
+    new_billing_interval = s.billing_interval
+FROM subscriptions s
+WHERE s.id = pc.subscription_id;
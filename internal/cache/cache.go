@@ -0,0 +1,17 @@
+// Package cache хранит ответы чтения с инвалидацией по тегам: запись
+// помечается тегами (пользователь, подписка), и изменение данных удаляет
+// все записи с затронутыми тегами.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type Cache interface {
+	// Get возвращает значение и признак попадания.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// InvalidateTags удаляет все записи, помеченные любым из тегов.
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
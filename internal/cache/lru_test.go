@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	if _, ok, _ := c.Get(ctx, "missing"); ok {
+		t.Fatal("Get(missing) hit, want miss")
+	}
+	if err := c.Set(ctx, "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, ok, _ := c.Get(ctx, "key")
+	if !ok || string(value) != "value" {
+		t.Fatalf("Get(key) = %q, ok %v; want value", value, ok)
+	}
+}
+
+func TestLRUTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	if err := c.Set(ctx, "key", []byte("value"), time.Millisecond, "tag"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := c.Get(ctx, "key"); ok {
+		t.Fatal("Get after TTL hit, want miss")
+	}
+	if len(c.entries) != 0 || len(c.tags) != 0 {
+		t.Fatalf("expired entry left %d entries and %d tags", len(c.entries), len(c.tags))
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("a"), time.Minute, "tag:a")
+	c.Set(ctx, "b", []byte("b"), time.Minute)
+	// Чтение делает a недавно использованной, поэтому вытесняется b.
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("c"), time.Minute)
+
+	for key, present := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != present {
+			t.Errorf("Get(%s) hit = %v, want %v", key, ok, present)
+		}
+	}
+
+	c.Set(ctx, "d", []byte("d"), time.Minute)
+	c.Set(ctx, "e", []byte("e"), time.Minute)
+	if _, ok := c.tags["tag:a"]; ok {
+		t.Fatal("evicted entry left its tag behind")
+	}
+}
+
+func TestLRUInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	c.Set(ctx, "a", []byte("a"), time.Minute, "user:1", "all")
+	c.Set(ctx, "b", []byte("b"), time.Minute, "user:2", "all")
+	c.Set(ctx, "c", []byte("c"), time.Minute, "user:1", "user:2", "all")
+
+	c.InvalidateTags(ctx, "user:1")
+	for key, present := range map[string]bool{"a": false, "b": true, "c": false} {
+		if _, ok, _ := c.Get(ctx, key); ok != present {
+			t.Errorf("Get(%s) hit = %v, want %v", key, ok, present)
+		}
+	}
+
+	c.InvalidateTags(ctx, "missing", "all")
+	if len(c.entries) != 0 || len(c.tags) != 0 {
+		t.Fatalf("InvalidateTags(all) left %d entries and %d tags", len(c.entries), len(c.tags))
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis — общий кэш для нескольких экземпляров сервиса. Работает с любым
+// сервером, совместимым с протоколом Redis. Для каждого тега хранится
+// множество ключей, которые он помечает.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.prefix+key, value, ttl)
+		for _, tag := range tags {
+			// Срок множества тега продлевается с каждой записью, поэтому при
+			// общем TTL оно живет не меньше своих записей.
+			pipe.SAdd(ctx, c.tagKey(tag), c.prefix+key)
+			pipe.Expire(ctx, c.tagKey(tag), ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *Redis) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		keys, err := c.client.SMembers(ctx, c.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, c.tagKey(tag))
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Redis) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
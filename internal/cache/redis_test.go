@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedis(client, "test:"), server
+}
+
+func TestRedisGetSet(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestRedis(t)
+
+	if _, ok, err := c.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = ok %v, err %v; want miss", ok, err)
+	}
+	if err := c.Set(ctx, "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, ok, err := c.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("Get(key) = %q, ok %v, err %v; want value", value, ok, err)
+	}
+}
+
+func TestRedisTTL(t *testing.T) {
+	ctx := context.Background()
+	c, server := newTestRedis(t)
+
+	if err := c.Set(ctx, "key", []byte("value"), time.Minute, "tag"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ttl := server.TTL("test:key"); ttl != time.Minute {
+		t.Fatalf("key TTL = %v, want %v", ttl, time.Minute)
+	}
+	if ttl := server.TTL("test:tag:tag"); ttl != time.Minute {
+		t.Fatalf("tag TTL = %v, want %v", ttl, time.Minute)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, ok, err := c.Get(ctx, "key"); err != nil || ok {
+		t.Fatalf("Get after TTL = ok %v, err %v; want miss", ok, err)
+	}
+	if server.Exists("test:tag:tag") {
+		t.Fatal("tag set outlived its entries")
+	}
+}
+
+func TestRedisInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	c, server := newTestRedis(t)
+
+	entries := map[string][]string{
+		"a": {"user:1", "all"},
+		"b": {"user:2", "all"},
+		"c": {"user:1", "user:2", "all"},
+	}
+	for key, tags := range entries {
+		if err := c.Set(ctx, key, []byte(key), time.Minute, tags...); err != nil {
+			t.Fatalf("Set(%s): %v", key, err)
+		}
+	}
+
+	if err := c.InvalidateTags(ctx, "user:1"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	assertRedisKeys(t, c, map[string]bool{"a": false, "b": true, "c": false})
+	if server.Exists("test:tag:user:1") {
+		t.Fatal("invalidated tag set was not deleted")
+	}
+
+	if err := c.InvalidateTags(ctx, "missing", "all"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	assertRedisKeys(t, c, map[string]bool{"a": false, "b": false, "c": false})
+}
+
+func assertRedisKeys(t *testing.T, c *Redis, want map[string]bool) {
+	t.Helper()
+	for key, present := range want {
+		_, ok, err := c.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", key, err)
+		}
+		if ok != present {
+			t.Errorf("Get(%s) hit = %v, want %v", key, ok, present)
+		}
+	}
+}
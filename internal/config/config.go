@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"subscribe_project/pkg/logger"
 
@@ -17,15 +19,48 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	ServerPort string
+
+	IdempotencyTTL time.Duration
+
+	MetricsRefreshInterval time.Duration
+
+	ServiceVersion      string
+	Environment         string
+	TracingExporter     string
+	TracingOTLPEndpoint string
+	TracingSampleRatio  float64
+
+	RateLimitEnabled bool
+	RateLimitStore   string
+	RateLimitDefault string
+	RateLimitRoutes  string
+
+	BudgetCheckInterval time.Duration
+	AlertWebhookURL     string
+
+	RollupRefreshInterval time.Duration
+
+	PriceInsightsInterval  time.Duration
+	PriceIncreaseThreshold float64
+
+	CacheBackend string
+	CacheTTL     time.Duration
+	CacheSize    int
+	RedisURL     string
+
+	DuplicateStrictness string
+}
+
+// LoadEnv загружает .env в окружение процесса, не перезаписывая заданные
+// переменные. Вызывается до logger.InitLogger: логгер тоже настраивается из
+// окружения.
+func LoadEnv() error {
+	return godotenv.Load(".env")
 }
 
 func LoadConfig() (*Config, error) {
 	logger.Log.Info("Loading configuration...")
 
-	if err := godotenv.Load(".env"); err != nil {
-		logger.Log.WithField("error", err).Warn(".env file not found, using environment variables")
-	}
-
 	config := &Config{
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
@@ -33,13 +68,54 @@ func LoadConfig() (*Config, error) {
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "subscriptions_db"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
+
+		IdempotencyTTL: getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		MetricsRefreshInterval: getDurationEnv("METRICS_REFRESH_INTERVAL", time.Minute),
+
+		ServiceVersion:      getEnv("SERVICE_VERSION", "dev"),
+		Environment:         getEnv("APP_ENV", "development"),
+		TracingExporter:     getEnv("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+		TracingSampleRatio:  getFloatEnv("TRACING_SAMPLE_RATIO", 1.0),
+
+		RateLimitEnabled: getBoolEnv("RATE_LIMIT_ENABLED", true),
+		RateLimitStore:   getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitDefault: getEnv("RATE_LIMIT_DEFAULT", "120/1m:30"),
+		RateLimitRoutes:  getEnv("RATE_LIMIT_ROUTES", "POST /api/summary=10/1m:5"),
+
+		BudgetCheckInterval: getDurationEnv("BUDGET_CHECK_INTERVAL", 15*time.Minute),
+		AlertWebhookURL:     getEnv("ALERT_WEBHOOK_URL", ""),
+
+		RollupRefreshInterval: getDurationEnv("ROLLUP_REFRESH_INTERVAL", time.Minute),
+
+		PriceInsightsInterval:  getDurationEnv("PRICE_INSIGHTS_INTERVAL", time.Hour),
+		PriceIncreaseThreshold: getFloatEnv("PRICE_INCREASE_THRESHOLD", 0.2),
+
+		CacheBackend: getEnv("CACHE_BACKEND", "memory"),
+		CacheTTL:     getDurationEnv("CACHE_TTL", time.Minute),
+		CacheSize:    getIntEnv("CACHE_SIZE", 10000),
+		RedisURL:     getEnv("REDIS_URL", "redis://localhost:6379/0"),
+
+		DuplicateStrictness: getEnv("DUPLICATE_STRICTNESS", "warn"),
 	}
 
 	logger.Log.WithFields(logrus.Fields{
-		"db_host":     config.DBHost,
-		"db_port":     config.DBPort,
-		"db_name":     config.DBName,
-		"server_port": config.ServerPort,
+		"db_host":          config.DBHost,
+		"db_port":          config.DBPort,
+		"db_name":          config.DBName,
+		"server_port":      config.ServerPort,
+		"idempotency_ttl":  config.IdempotencyTTL.String(),
+		"metrics_refresh":  config.MetricsRefreshInterval.String(),
+		"tracing":          config.TracingExporter,
+		"rate_limit":       config.RateLimitEnabled,
+		"rate_limit_store": config.RateLimitStore,
+		"budget_check":     config.BudgetCheckInterval.String(),
+		"alert_webhook":    config.AlertWebhookURL != "",
+		"rollup_refresh":   config.RollupRefreshInterval.String(),
+		"price_insights":   config.PriceInsightsInterval.String(),
+		"cache":            config.CacheBackend,
+		"duplicates":       config.DuplicateStrictness,
 	}).Info("Configuration loaded successfully")
 
 	return config, nil
@@ -62,7 +138,7 @@ func (c *Config) GetDBConnectionString() string {
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 
-		if key == "DB_PASSWORD" {
+		if key == "DB_PASSWORD" || key == "REDIS_URL" {
 			logger.Log.WithField(key, "***").Debug("Loaded environment variable")
 		} else {
 			logger.Log.WithField(key, value).Debug("Loaded environment variable")
@@ -77,3 +153,67 @@ func getEnv(key, defaultValue string) string {
 
 	return defaultValue
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key, defaultValue.String())
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"key":           key,
+			"value":         value,
+			"default_value": defaultValue.String(),
+		}).Warn("Invalid duration in environment variable, using default")
+		return defaultValue
+	}
+
+	return duration
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	value := getEnv(key, strconv.Itoa(defaultValue))
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"key":           key,
+			"value":         value,
+			"default_value": defaultValue,
+		}).Warn("Invalid integer in environment variable, using default")
+		return defaultValue
+	}
+
+	return number
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	value := getEnv(key, strconv.FormatFloat(defaultValue, 'f', -1, 64))
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"key":           key,
+			"value":         value,
+			"default_value": defaultValue,
+		}).Warn("Invalid number in environment variable, using default")
+		return defaultValue
+	}
+
+	return number
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value := getEnv(key, strconv.FormatBool(defaultValue))
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"key":           key,
+			"value":         value,
+			"default_value": defaultValue,
+		}).Warn("Invalid boolean in environment variable, using default")
+		return defaultValue
+	}
+
+	return flag
+}
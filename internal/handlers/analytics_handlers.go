@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AnalyticsHandler struct {
+	service services.AnalyticsService
+}
+
+func NewAnalyticsHandler(service services.AnalyticsService) *AnalyticsHandler {
+	logger.Log.WithField("component", "analytics_handler").Info("Creating new analytics handler")
+	return &AnalyticsHandler{service: service}
+}
+
+// GetMRR получает динамику MRR
+// @Summary Динамика MRR
+// @Description Возвращает помесячную регулярную выручку: цены активных подписок, приведенные к месяцу по интервалу оплаты, с учетом фаз и пауз. По умолчанию — последние 12 месяцев
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Начальный месяц (MM-YYYY)"
+// @Param end_date query string false "Конечный месяц (MM-YYYY), по умолчанию текущий"
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {array} models.MRRPoint
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /analytics/mrr [get]
+func (h *AnalyticsHandler) GetMRR(c *fiber.Ctx) error {
+	points, err := h.service.MRR(c.UserContext(), analyticsRequest(c))
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetMRR",
+		}).Error("Service failed to calculate MRR")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(points)
+}
+
+// GetSubscriptionFlow получает динамику новых и отмененных подписок
+// @Summary Новые и отмененные подписки
+// @Description Возвращает по месяцам число новых подписок, подписок, закончившихся в месяце, и их разницу. По умолчанию — последние 12 месяцев
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Начальный месяц (MM-YYYY)"
+// @Param end_date query string false "Конечный месяц (MM-YYYY), по умолчанию текущий"
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {array} models.SubscriptionFlowPoint
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /analytics/subscriptions [get]
+func (h *AnalyticsHandler) GetSubscriptionFlow(c *fiber.Ctx) error {
+	points, err := h.service.SubscriptionFlow(c.UserContext(), analyticsRequest(c))
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetSubscriptionFlow",
+		}).Error("Service failed to calculate subscription flow")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(points)
+}
+
+// GetChurn получает отток по сервисам
+// @Summary Отток по сервисам
+// @Description Возвращает для каждого сервиса помесячную долю активных подписок, закончившихся в месяце, и общий отток за период. По умолчанию — последние 12 месяцев
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Начальный месяц (MM-YYYY)"
+// @Param end_date query string false "Конечный месяц (MM-YYYY), по умолчанию текущий"
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {array} models.ServiceChurn
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /analytics/churn [get]
+func (h *AnalyticsHandler) GetChurn(c *fiber.Ctx) error {
+	churn, err := h.service.Churn(c.UserContext(), analyticsRequest(c))
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetChurn",
+		}).Error("Service failed to calculate churn")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(churn)
+}
+
+func analyticsRequest(c *fiber.Ctx) models.AnalyticsRequest {
+	var req models.AnalyticsRequest
+	if startDate := c.Query("start_date"); startDate != "" {
+		req.StartDate = &startDate
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		req.EndDate = &endDate
+	}
+	if serviceName := c.Query("service_name"); serviceName != "" {
+		req.ServiceName = &serviceName
+	}
+	return req
+}
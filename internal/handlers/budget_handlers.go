@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetHandler struct {
+	service services.BudgetService
+}
+
+func NewBudgetHandler(service services.BudgetService) *BudgetHandler {
+	logger.Log.WithField("component", "budget_handler").Info("Creating new budget handler")
+	return &BudgetHandler{service: service}
+}
+
+// CreateBudget создает бюджет
+// @Summary Создать бюджет
+// @Description Создает бюджет пользователя на месяц или год: на все подписки, на категорию (с подкатегориями) или на сервис
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param request body models.CreateBudgetRequest true "Данные бюджета"
+// @Success 201 {object} models.Budget
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *fiber.Ctx) error {
+	var req models.CreateBudgetRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateBudget",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	budget, err := h.service.CreateBudget(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateBudget",
+		}).Error("Service failed to create budget")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(budget)
+}
+
+// ListBudgets получает список бюджетов
+// @Summary Список бюджетов
+// @Description Возвращает бюджеты, при необходимости только одного пользователя
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {array} models.Budget
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(c *fiber.Ctx) error {
+	var userID *string
+	if id := c.Query("user_id"); id != "" {
+		userID = &id
+	}
+
+	budgets, err := h.service.ListBudgets(c.UserContext(), userID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListBudgets",
+		}).Error("Service failed to list budgets")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(budgets)
+}
+
+// ListBudgetStatuses получает состояние бюджетов пользователя
+// @Summary Состояние бюджетов пользователя
+// @Description Возвращает для каждого бюджета пользователя траты с начала периода, прогноз до его конца и состояние: ok, warning (от 80%) или exceeded (от 100%)
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id query string true "ID пользователя"
+// @Success 200 {array} models.BudgetStatus
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /budgets/status [get]
+func (h *BudgetHandler) ListBudgetStatuses(c *fiber.Ctx) error {
+	userID := c.Query("user_id")
+	if userID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "user_id is required",
+		})
+	}
+
+	statuses, err := h.service.ListBudgetStatuses(c.UserContext(), userID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListBudgetStatuses",
+		}).Error("Service failed to evaluate budgets")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(statuses)
+}
+
+// GetBudget получает бюджет по ID
+// @Summary Получить бюджет
+// @Description Возвращает бюджет по его ID
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} models.Budget
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Бюджет не найден"
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	budget, err := h.service.GetBudget(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetBudget",
+		}).Warn("Failed to get budget")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(budget)
+}
+
+// GetBudgetStatus получает состояние бюджета
+// @Summary Состояние бюджета
+// @Description Возвращает траты с начала текущего периода бюджета, прогноз до конца периода и состояние бюджета
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} models.BudgetStatus
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Бюджет не найден"
+// @Router /budgets/{id}/status [get]
+func (h *BudgetHandler) GetBudgetStatus(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	status, err := h.service.GetBudgetStatus(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetBudgetStatus",
+		}).Warn("Failed to evaluate budget")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(status)
+}
+
+// UpdateBudget обновляет бюджет
+// @Summary Обновить бюджет
+// @Description Меняет период или сумму бюджета. Область бюджета не меняется
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param request body models.UpdateBudgetRequest true "Данные для обновления"
+// @Success 200 {object} models.Budget
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Бюджет не найден"
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateBudgetRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateBudget",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	budget, err := h.service.UpdateBudget(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateBudget",
+		}).Error("Service failed to update budget")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(budget)
+}
+
+// DeleteBudget удаляет бюджет
+// @Summary Удалить бюджет
+// @Description Удаляет бюджет вместе с историей уведомлений по нему
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Бюджет не найден"
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeleteBudget(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeleteBudget",
+		}).Error("Service failed to delete budget")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Budget deleted successfully"})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"strconv"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CatalogHandler struct {
+	service services.CatalogService
+}
+
+func NewCatalogHandler(service services.CatalogService) *CatalogHandler {
+	logger.Log.WithField("component", "catalog_handler").Info("Creating new catalog handler")
+	return &CatalogHandler{service: service}
+}
+
+// CreateService добавляет сервис в каталог
+// @Summary Добавить сервис в каталог
+// @Description Создает запись каталога с каноническим именем, алиасами и метаданными
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param request body models.CreateServiceRequest true "Данные сервиса"
+// @Success 201 {object} models.Service
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Имя или алиас уже занят"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(c *fiber.Ctx) error {
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+		"handler": "CreateService",
+		"method":  c.Method(),
+		"path":    c.Path(),
+	}).Info("Received request to create catalog service")
+
+	var req models.CreateServiceRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateService",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	service, err := h.service.CreateService(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateService",
+			"name":    req.Name,
+		}).Error("Service failed to create catalog service")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(service)
+}
+
+// GetService получает сервис каталога по ID
+// @Summary Получить сервис каталога
+// @Description Возвращает запись каталога по её ID
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} models.Service
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Сервис не найден"
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	service, err := h.service.GetService(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetService",
+			"id":      id,
+		}).Warn("Failed to get catalog service")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(service)
+}
+
+// UpdateService обновляет сервис каталога
+// @Summary Обновить сервис каталога
+// @Description Обновляет имя, алиасы и метаданные сервиса. Переименование переносится в привязанные подписки
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param request body models.UpdateServiceRequest true "Данные для обновления"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Сервис не найден"
+// @Failure 409 {object} map[string]string "Имя или алиас уже занят"
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateServiceRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateService",
+			"id":      id,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.service.UpdateService(c.UserContext(), id, req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateService",
+			"id":      id,
+		}).Error("Service failed to update catalog service")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Service updated successfully"})
+}
+
+// DeleteService удаляет сервис из каталога
+// @Summary Удалить сервис каталога
+// @Description Удаляет запись каталога. Подписки сохраняют название, но теряют привязку
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Сервис не найден"
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeleteService(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeleteService",
+			"id":      id,
+		}).Error("Service failed to delete catalog service")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Service deleted successfully"})
+}
+
+// ListServices получает список сервисов каталога
+// @Summary Список сервисов каталога
+// @Description Возвращает сервисы каталога с пагинацией
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.Service
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	services, err := h.service.ListServices(c.UserContext(), page, limit)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListServices",
+		}).Error("Service failed to list catalog services")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(services)
+}
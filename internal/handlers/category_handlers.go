@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CategoryHandler struct {
+	service services.CategoryService
+}
+
+func NewCategoryHandler(service services.CategoryService) *CategoryHandler {
+	logger.Log.WithField("component", "category_handler").Info("Creating new category handler")
+	return &CategoryHandler{service: service}
+}
+
+// CreateCategory создает категорию
+// @Summary Создать категорию
+// @Description Создает категорию подписок. Slug формируется из имени, если не указан
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCategoryRequest true "Данные категории"
+// @Success 201 {object} models.Category
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Slug уже занят"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /categories [post]
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+		"handler": "CreateCategory",
+		"method":  c.Method(),
+		"path":    c.Path(),
+	}).Info("Received request to create category")
+
+	var req models.CreateCategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateCategory",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	category, err := h.service.CreateCategory(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateCategory",
+			"name":    req.Name,
+		}).Error("Service failed to create category")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+// GetCategory получает категорию по ID
+// @Summary Получить категорию
+// @Description Возвращает категорию по её ID
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "ID категории"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Категория не найдена"
+// @Router /categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	category, err := h.service.GetCategory(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetCategory",
+			"id":      id,
+		}).Warn("Failed to get category")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(category)
+}
+
+// UpdateCategory обновляет категорию
+// @Summary Обновить категорию
+// @Description Обновляет имя, slug или родителя категории. Пустой parent_id делает категорию корневой
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "ID категории"
+// @Param request body models.UpdateCategoryRequest true "Данные для обновления"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный запрос или цикл в дереве"
+// @Failure 404 {object} map[string]string "Категория не найдена"
+// @Failure 409 {object} map[string]string "Slug уже занят"
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateCategoryRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateCategory",
+			"id":      id,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.service.UpdateCategory(c.UserContext(), id, req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateCategory",
+			"id":      id,
+		}).Error("Service failed to update category")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Category updated successfully"})
+}
+
+// DeleteCategory удаляет категорию
+// @Summary Удалить категорию
+// @Description Удаляет категорию. Подкатегории становятся корневыми, подписки теряют категорию
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "ID категории"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Категория не найдена"
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeleteCategory(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeleteCategory",
+			"id":      id,
+		}).Error("Service failed to delete category")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Category deleted successfully"})
+}
+
+// ListCategories получает список категорий
+// @Summary Список категорий
+// @Description Возвращает все категории; иерархия задается полем parent_id
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Category
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
+	categories, err := h.service.ListCategories(c.UserContext())
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListCategories",
+		}).Error("Service failed to list categories")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(categories)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// ListDuplicates получает отчет о возможных дубликатах
+// @Summary Возможные дубликаты подписок
+// @Description Возвращает пары подписок одного пользователя на один сервис (по каталогу или совпадающему названию) с пересекающимися периодами
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {array} models.DuplicatePair
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /duplicates [get]
+func (h *SubscriptionHandler) ListDuplicates(c *fiber.Ctx) error {
+	var userID *string
+	if id := c.Query("user_id"); id != "" {
+		userID = &id
+	}
+
+	pairs, err := h.service.ListDuplicates(c.UserContext(), userID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListDuplicates",
+		}).Error("Service failed to list duplicates")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(pairs)
+}
+
+// MergeSubscriptions объединяет две подписки
+// @Summary Объединить дубликаты
+// @Description Переносит платежи, историю, теги, участников и паузы подписки merge_id на keep_id, расширяет ее период и удаляет дубликат. Периоды подписок должны пересекаться или идти подряд. Начало подписки с фазами не переносится. Снимок удаленной подписки сохраняется в истории объединений
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body models.MergeSubscriptionsRequest true "Оставляемая и объединяемая подписки"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Подписки нельзя объединить"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /duplicates/merge [post]
+func (h *SubscriptionHandler) MergeSubscriptions(c *fiber.Ctx) error {
+	var req models.MergeSubscriptionsRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "MergeSubscriptions",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	subscription, err := h.service.MergeSubscriptions(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":    err.Error(),
+			"handler":  "MergeSubscriptions",
+			"keep_id":  req.KeepID,
+			"merge_id": req.MergeID,
+		}).Error("Service failed to merge subscriptions")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// ListMerges получает историю объединений подписки
+// @Summary История объединений подписки
+// @Description Возвращает подписки, объединенные с данной, вместе со снимками их состояния до объединения
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} models.SubscriptionMerge
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /subscriptions/{id}/merges [get]
+func (h *SubscriptionHandler) ListMerges(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	merges, err := h.service.ListMerges(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListMerges",
+			"id":      id,
+		}).Warn("Failed to list subscription merges")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(merges)
+}
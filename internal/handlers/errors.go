@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+
+	"subscribe_project/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorStatus сопоставляет ошибки сервисного слоя HTTP-статусам.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrConflict):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"strconv"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type EventHandler struct {
+	service services.EventService
+}
+
+func NewEventHandler(service services.EventService) *EventHandler {
+	logger.Log.WithField("component", "event_handler").Info("Creating new event handler")
+	return &EventHandler{service: service}
+}
+
+// ListUpcomingEvents получает предстоящие события по подпискам
+// @Summary Предстоящие события
+// @Description Возвращает ближайшие события по подпискам: окончание пробного периода и вводной цены, а также списания, до которых истечет способ оплаты
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param days query int false "Горизонт в днях" default(30)
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {array} models.UpcomingEvent
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /events/upcoming [get]
+func (h *EventHandler) ListUpcomingEvents(c *fiber.Ctx) error {
+	days, _ := strconv.Atoi(c.Query("days", "30"))
+
+	req := models.UpcomingEventsRequest{Days: days}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+
+	events, err := h.service.ListUpcoming(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListUpcomingEvents",
+		}).Error("Service failed to list upcoming events")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(events)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"strconv"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ForecastHandler struct {
+	service services.ForecastService
+}
+
+func NewForecastHandler(service services.ForecastService) *ForecastHandler {
+	logger.Log.WithField("component", "forecast_handler").Info("Creating new forecast handler")
+	return &ForecastHandler{service: service}
+}
+
+// GetForecast прогнозирует расходы на подписки
+// @Summary Прогноз расходов
+// @Description Возвращает помесячный прогноз расходов начиная с текущего месяца с разбивкой по сервисам. Учитываются даты окончания, пробные периоды и вводные цены, паузы и интервалы оплаты; для пользователя — только его доля в общих подписках. Из будущих изменений цены учитывается только окончание пробного периода и вводной цены: обычная цена считается неизменной до конца прогноза, запланировать ее изменение нельзя
+// @Tags forecast
+// @Accept json
+// @Produce json
+// @Param months query int false "Число месяцев (1-36)" default(12)
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {object} models.Forecast
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /forecast [get]
+func (h *ForecastHandler) GetForecast(c *fiber.Ctx) error {
+	months, err := strconv.Atoi(c.Query("months", "0"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "months must be a number",
+		})
+	}
+
+	req := models.ForecastRequest{Months: months}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if serviceName := c.Query("service_name"); serviceName != "" {
+		req.ServiceName = &serviceName
+	}
+
+	forecast, err := h.service.Forecast(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetForecast",
+		}).Error("Service failed to forecast costs")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(forecast)
+}
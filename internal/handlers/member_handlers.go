@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type MemberHandler struct {
+	service services.MemberService
+}
+
+func NewMemberHandler(service services.MemberService) *MemberHandler {
+	logger.Log.WithField("component", "member_handler").Info("Creating new member handler")
+	return &MemberHandler{service: service}
+}
+
+// GetMembers получает участников общей подписки
+// @Summary Участники подписки
+// @Description Возвращает правило разделения и доли владельца и участников подписки
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} models.SubscriptionMembers
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members [get]
+func (h *MemberHandler) GetMembers(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	members, err := h.service.GetMembers(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetMembers",
+			"id":      id,
+		}).Warn("Failed to get subscription members")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(members)
+}
+
+// SetMembers задает участников общей подписки
+// @Summary Задать участников подписки
+// @Description Заменяет список участников и правило разделения стоимости (equal, percentage, fixed). Владелец оплачивает остаток
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.SetMembersRequest true "Участники и правило разделения"
+// @Success 200 {object} models.SubscriptionMembers
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members [put]
+func (h *MemberHandler) SetMembers(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.SetMembersRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetMembers",
+			"id":      id,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	members, err := h.service.SetMembers(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetMembers",
+			"id":      id,
+		}).Error("Service failed to set subscription members")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(members)
+}
+
+// RemoveMember исключает участника из общей подписки
+// @Summary Исключить участника подписки
+// @Description Удаляет пользователя из участников подписки; его доля переходит владельцу
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param user_id path string true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Участник не найден"
+// @Router /subscriptions/{id}/members/{user_id} [delete]
+func (h *MemberHandler) RemoveMember(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userID := c.Params("user_id")
+
+	if err := h.service.RemoveMember(c.UserContext(), id, userID); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "RemoveMember",
+			"id":      id,
+		}).Error("Service failed to remove subscription member")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Member removed successfully"})
+}
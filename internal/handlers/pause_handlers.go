@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PauseHandler struct {
+	service services.PauseService
+}
+
+func NewPauseHandler(service services.PauseService) *PauseHandler {
+	logger.Log.WithField("component", "pause_handler").Info("Creating new pause handler")
+	return &PauseHandler{service: service}
+}
+
+// PauseSubscription приостанавливает подписку
+// @Summary Приостановить подписку
+// @Description Приостанавливает оплату с указанного месяца (по умолчанию текущего). С resume_date пауза завершится автоматически. Месяцы паузы не учитываются в сводке
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.PauseSubscriptionRequest false "Период паузы"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Подписка уже на паузе в этом периоде"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/pause [post]
+func (h *PauseHandler) PauseSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.PauseSubscriptionRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+				"error":   err.Error(),
+				"handler": "PauseSubscription",
+				"id":      id,
+			}).Error("Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	subscription, err := h.service.PauseSubscription(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "PauseSubscription",
+			"id":      id,
+		}).Error("Service failed to pause subscription")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// ResumeSubscription возобновляет подписку
+// @Summary Возобновить подписку
+// @Description Возобновляет оплату с указанного месяца (по умолчанию текущего). Еще не начавшаяся пауза отменяется
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.ResumeSubscriptionRequest false "Месяц возобновления"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Подписка не на паузе"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/resume [post]
+func (h *PauseHandler) ResumeSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.ResumeSubscriptionRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+				"error":   err.Error(),
+				"handler": "ResumeSubscription",
+				"id":      id,
+			}).Error("Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	subscription, err := h.service.ResumeSubscription(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ResumeSubscription",
+			"id":      id,
+		}).Error("Service failed to resume subscription")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// ListPauses получает историю пауз подписки
+// @Summary Паузы подписки
+// @Description Возвращает прошлые, текущие и запланированные паузы подписки
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} models.SubscriptionPause
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /subscriptions/{id}/pauses [get]
+func (h *PauseHandler) ListPauses(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	pauses, err := h.service.ListPauses(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListPauses",
+			"id":      id,
+		}).Warn("Failed to list subscription pauses")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(pauses)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"strconv"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentHandler struct {
+	service services.PaymentService
+}
+
+func NewPaymentHandler(service services.PaymentService) *PaymentHandler {
+	logger.Log.WithField("component", "payment_handler").Info("Creating new payment handler")
+	return &PaymentHandler{service: service}
+}
+
+// CreatePayment записывает платеж
+// @Summary Записать платеж
+// @Description Записывает фактическое списание по подписке. Валюта по умолчанию RUB
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePaymentRequest true "Данные платежа"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /payments [post]
+func (h *PaymentHandler) CreatePayment(c *fiber.Ctx) error {
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+		"handler": "CreatePayment",
+		"method":  c.Method(),
+		"path":    c.Path(),
+	}).Info("Received request to record payment")
+
+	var req models.CreatePaymentRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreatePayment",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	payment, err := h.service.CreatePayment(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreatePayment",
+		}).Error("Service failed to record payment")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(payment)
+}
+
+// GetPayment получает платеж по ID
+// @Summary Получить платеж
+// @Description Возвращает платеж по его ID
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "ID платежа"
+// @Success 200 {object} models.Payment
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Платеж не найден"
+// @Router /payments/{id} [get]
+func (h *PaymentHandler) GetPayment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	payment, err := h.service.GetPayment(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetPayment",
+		}).Warn("Failed to get payment")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(payment)
+}
+
+// DeletePayment удаляет платеж
+// @Summary Удалить платеж
+// @Description Удаляет ошибочно записанный платеж
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "ID платежа"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Платеж не найден"
+// @Router /payments/{id} [delete]
+func (h *PaymentHandler) DeletePayment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeletePayment(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeletePayment",
+		}).Error("Service failed to delete payment")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Payment deleted successfully"})
+}
+
+// ListPayments получает список платежей
+// @Summary Список платежей
+// @Description Возвращает платежи с фильтрами по подписке, владельцу и дате платежа
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Param subscription_id query string false "ID подписки"
+// @Param user_id query string false "ID владельца подписки"
+// @Param from query string false "Дата платежа с (YYYY-MM-DD)"
+// @Param to query string false "Дата платежа по (YYYY-MM-DD)"
+// @Success 200 {array} models.Payment
+// @Failure 400 {object} map[string]string "Некорректный фильтр"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /payments [get]
+func (h *PaymentHandler) ListPayments(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	req := models.ListPaymentsRequest{Page: page, Limit: limit}
+	if subscriptionID := c.Query("subscription_id"); subscriptionID != "" {
+		req.SubscriptionID = &subscriptionID
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if from := c.Query("from"); from != "" {
+		req.From = &from
+	}
+	if to := c.Query("to"); to != "" {
+		req.To = &to
+	}
+
+	payments, err := h.service.ListPayments(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListPayments",
+		}).Error("Service failed to list payments")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(payments)
+}
+
+// Reconcile сверяет платежи с ожидаемыми списаниями
+// @Summary Сверка платежей
+// @Description Сравнивает ожидаемые помесячные списания (с учетом фаз и пауз) с записанными платежами. Статус месяца: ok, missing (нет платежа), duplicate (лишние платежи), mismatch (сумма или валюта не совпадает), unexpected (платеж без ожидаемого списания)
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param start_date query string true "Начало периода (MM-YYYY)"
+// @Param end_date query string true "Конец периода (MM-YYYY)"
+// @Param user_id query string false "ID владельца подписки"
+// @Param subscription_id query string false "ID подписки"
+// @Success 200 {object} models.ReconciliationReport
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /payments/reconciliation [get]
+func (h *PaymentHandler) Reconcile(c *fiber.Ctx) error {
+	req := models.ReconciliationRequest{
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if subscriptionID := c.Query("subscription_id"); subscriptionID != "" {
+		req.SubscriptionID = &subscriptionID
+	}
+
+	report, err := h.service.Reconcile(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "Reconcile",
+		}).Error("Service failed to reconcile payments")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(report)
+}
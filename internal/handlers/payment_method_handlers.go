@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentMethodHandler struct {
+	service services.PaymentMethodService
+}
+
+func NewPaymentMethodHandler(service services.PaymentMethodService) *PaymentMethodHandler {
+	logger.Log.WithField("component", "payment_method_handler").Info("Creating new payment method handler")
+	return &PaymentMethodHandler{service: service}
+}
+
+// CreatePaymentMethod добавляет способ оплаты пользователю
+// @Summary Добавить способ оплаты
+// @Description Добавляет пользователю карту, счет или кошелек. Название хранится только маскированным, полные номера отклоняются
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body models.CreatePaymentMethodRequest true "Способ оплаты"
+// @Success 201 {object} models.PaymentMethod
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /users/{id}/payment-methods [post]
+func (h *PaymentMethodHandler) CreatePaymentMethod(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	var req models.CreatePaymentMethodRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreatePaymentMethod",
+			"user_id": userID,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	method, err := h.service.CreatePaymentMethod(c.UserContext(), userID, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreatePaymentMethod",
+			"user_id": userID,
+		}).Error("Service failed to create payment method")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(method)
+}
+
+// ListUserPaymentMethods получает способы оплаты пользователя
+// @Summary Способы оплаты пользователя
+// @Description Возвращает все способы оплаты пользователя
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} models.PaymentMethod
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Router /users/{id}/payment-methods [get]
+func (h *PaymentMethodHandler) ListUserPaymentMethods(c *fiber.Ctx) error {
+	userID := c.Params("id")
+
+	methods, err := h.service.ListUserPaymentMethods(c.UserContext(), userID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListUserPaymentMethods",
+			"user_id": userID,
+		}).Warn("Failed to list payment methods")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(methods)
+}
+
+// GetPaymentMethod получает способ оплаты по ID
+// @Summary Получить способ оплаты
+// @Description Возвращает способ оплаты по его ID. Привязанные подписки: GET /subscriptions?payment_method_id=
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID способа оплаты"
+// @Success 200 {object} models.PaymentMethod
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Способ оплаты не найден"
+// @Router /payment-methods/{id} [get]
+func (h *PaymentMethodHandler) GetPaymentMethod(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	method, err := h.service.GetPaymentMethod(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetPaymentMethod",
+		}).Warn("Failed to get payment method")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(method)
+}
+
+// UpdatePaymentMethod обновляет способ оплаты
+// @Summary Обновить способ оплаты
+// @Description Меняет название или срок действия, например после перевыпуска карты. Привязанные подписки сохраняются
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID способа оплаты"
+// @Param request body models.UpdatePaymentMethodRequest true "Данные для обновления"
+// @Success 200 {object} models.PaymentMethod
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Способ оплаты не найден"
+// @Router /payment-methods/{id} [put]
+func (h *PaymentMethodHandler) UpdatePaymentMethod(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdatePaymentMethodRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdatePaymentMethod",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	method, err := h.service.UpdatePaymentMethod(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdatePaymentMethod",
+		}).Error("Service failed to update payment method")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(method)
+}
+
+// DeletePaymentMethod удаляет способ оплаты
+// @Summary Удалить способ оплаты
+// @Description Удаляет способ оплаты; подписки остаются без привязки к нему
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param id path string true "ID способа оплаты"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Способ оплаты не найден"
+// @Router /payment-methods/{id} [delete]
+func (h *PaymentMethodHandler) DeletePaymentMethod(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeletePaymentMethod(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeletePaymentMethod",
+		}).Error("Service failed to delete payment method")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Payment method deleted successfully"})
+}
+
+// ListExpiring получает подписки с истекающими способами оплаты
+// @Summary Истекающие способы оплаты
+// @Description Возвращает подписки, способ оплаты которых истечет раньше ближайшего платного списания (в пределах 12 месяцев)
+// @Tags payment-methods
+// @Accept json
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {array} models.ExpiringPaymentMethod
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /payment-methods/expiring [get]
+func (h *PaymentMethodHandler) ListExpiring(c *fiber.Ctx) error {
+	var userID *string
+	if id := c.Query("user_id"); id != "" {
+		userID = &id
+	}
+
+	expiring, err := h.service.ListExpiring(c.UserContext(), userID)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListExpiring",
+		}).Error("Service failed to list expiring payment methods")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(expiring)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PhaseHandler struct {
+	service services.PhaseService
+}
+
+func NewPhaseHandler(service services.PhaseService) *PhaseHandler {
+	logger.Log.WithField("component", "phase_handler").Info("Creating new phase handler")
+	return &PhaseHandler{service: service}
+}
+
+// GetPhases получает расписание цен подписки
+// @Summary Фазы подписки
+// @Description Возвращает пробный период, вводные цены и обычную цену подписки с датами начала и окончания
+// @Tags phases
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} models.SubscriptionPhase
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/phases [get]
+func (h *PhaseHandler) GetPhases(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	phases, err := h.service.GetPhases(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetPhases",
+			"id":      id,
+		}).Warn("Failed to get subscription phases")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(phases)
+}
+
+// SetPhases задает начальные фазы подписки
+// @Summary Задать фазы подписки
+// @Description Заменяет пробный период и вводные цены. Фазы идут подряд от даты начала подписки, после них действует обычная цена
+// @Tags phases
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.SetPhasesRequest true "Начальные фазы"
+// @Success 200 {array} models.SubscriptionPhase
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/phases [put]
+func (h *PhaseHandler) SetPhases(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.SetPhasesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetPhases",
+			"id":      id,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	phases, err := h.service.SetPhases(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetPhases",
+			"id":      id,
+		}).Error("Service failed to set subscription phases")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(phases)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PriceInsightHandler struct {
+	service services.PriceInsightService
+}
+
+func NewPriceInsightHandler(service services.PriceInsightService) *PriceInsightHandler {
+	logger.Log.WithField("component", "price_insight_handler").Info("Creating new price insight handler")
+	return &PriceInsightHandler{service: service}
+}
+
+// GetPriceInsights получает аномалии цен
+// @Summary Аномалии цен
+// @Description Возвращает распределение месячной цены действующих подписок по сервисам и найденные фоновой задачей аномалии: outlier — подписка заметно дороже, чем у других пользователей сервиса; increase — резкое повышение цены подписки; service_increase — повышение цены сразу у нескольких пользователей сервиса. Для пользователя возвращаются аномалии его подписок и повышения цен его сервисов
+// @Tags insights
+// @Accept json
+// @Produce json
+// @Param service_name query string false "Название сервиса"
+// @Param user_id query string false "ID пользователя"
+// @Param kind query string false "Тип аномалии" Enums(outlier, increase, service_increase)
+// @Success 200 {object} models.PriceInsights
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /insights/prices [get]
+func (h *PriceInsightHandler) GetPriceInsights(c *fiber.Ctx) error {
+	var req models.PriceInsightsRequest
+	if serviceName := c.Query("service_name"); serviceName != "" {
+		req.ServiceName = &serviceName
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if kind := c.Query("kind"); kind != "" {
+		req.Kind = &kind
+	}
+
+	insights, err := h.service.GetInsights(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetPriceInsights",
+		}).Error("Service failed to get price insights")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(insights)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RecommendationHandler struct {
+	service services.RecommendationService
+}
+
+func NewRecommendationHandler(service services.RecommendationService) *RecommendationHandler {
+	logger.Log.WithField("component", "recommendation_handler").Info("Creating new recommendation handler")
+	return &RecommendationHandler{service: service}
+}
+
+// GetRecommendations получает рекомендации по экономии
+// @Summary Рекомендации по экономии
+// @Description Возвращает советы по действующим подпискам пользователя с оценкой экономии: yearly_plan — перейти на годовой план из каталога, category_overlap — отказаться от сервиса, пересекающегося по категории с более дорогим, unused — отменить подписку, отмеченную как неиспользуемая или без использований 30 дней. Экономия считается по обычной цене и доле пользователя; общая экономия учитывает каждую подписку один раз
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.Recommendations
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /users/{id}/recommendations [get]
+func (h *RecommendationHandler) GetRecommendations(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	recommendations, err := h.service.GetRecommendations(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetRecommendations",
+			"id":      id,
+		}).Error("Service failed to build recommendations")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(recommendations)
+}
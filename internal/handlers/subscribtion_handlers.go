@@ -28,12 +28,15 @@ func NewSubscriptionHandler(service services.SubscriptionService) *SubscriptionH
 // @Accept json
 // @Produce json
 // @Param request body models.CreateSubscriptionRequest true "Данные для создания подписки"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасных повторов"
 // @Success 201 {object} models.Subscription
 // @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Запрос с этим ключом еще обрабатывается"
+// @Failure 422 {object} map[string]string "Ключ уже использован с другим телом запроса"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "CreateSubscription",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -43,7 +46,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 	var req models.CreateSubscriptionRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "CreateSubscription",
 			"method":  c.Method(),
@@ -54,27 +57,27 @@ func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":      "CreateSubscription",
 		"service_name": req.ServiceName,
 		"user_id":      req.UserID,
 		"price":        req.Price,
 	}).Debug("Request body parsed successfully")
 
-	subscription, err := h.service.CreateSubscription(c.Context(), req)
+	subscription, err := h.service.CreateSubscription(c.UserContext(), req)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":        err.Error(),
 			"handler":      "CreateSubscription",
 			"service_name": req.ServiceName,
 			"user_id":      req.UserID,
 		}).Error("Service failed to create subscription")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":         "CreateSubscription",
 		"subscription_id": subscription.ID.String(),
 		"status_code":     fiber.StatusCreated,
@@ -97,7 +100,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "GetSubscription",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -106,7 +109,7 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 	}).Info("Received request to get subscription")
 
 	if _, err := uuid.Parse(id); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "GetSubscription",
 			"id":      id,
@@ -116,9 +119,9 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	subscription, err := h.service.GetSubscription(c.Context(), id)
+	subscription, err := h.service.GetSubscription(c.UserContext(), id)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "GetSubscription",
 			"id":      id,
@@ -128,7 +131,7 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":         "GetSubscription",
 		"subscription_id": id,
 		"service_name":    subscription.ServiceName,
@@ -140,7 +143,7 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 
 // UpdateSubscription обновляет подписку
 // @Summary Обновить подписку
-// @Description Обновляет информацию о подписке
+// @Description Обновляет информацию о подписке. end_date нельзя изменить так, чтобы сменился статус подписки: для отмены и возобновления есть отдельные эндпоинты
 // @Tags subscriptions
 // @Accept json
 // @Produce json
@@ -148,12 +151,13 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 // @Param request body models.UpdateSubscriptionRequest true "Данные для обновления"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Изменение end_date меняет статус подписки"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "UpdateSubscription",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -164,7 +168,7 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 	var req models.UpdateSubscriptionRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "UpdateSubscription",
 			"id":      id,
@@ -174,7 +178,7 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":          "UpdateSubscription",
 		"id":               id,
 		"has_service_name": req.ServiceName != nil,
@@ -182,18 +186,18 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 		"has_end_date":     req.EndDate != nil,
 	}).Debug("Request body parsed successfully")
 
-	if err := h.service.UpdateSubscription(c.Context(), id, req); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+	if err := h.service.UpdateSubscription(c.UserContext(), id, req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "UpdateSubscription",
 			"id":      id,
 		}).Error("Service failed to update subscription")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":     "UpdateSubscription",
 		"id":          id,
 		"status_code": fiber.StatusOK,
@@ -216,7 +220,7 @@ func (h *SubscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
 func (h *SubscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "DeleteSubscription",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -224,8 +228,8 @@ func (h *SubscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 		"ip":      c.IP(),
 	}).Info("Received request to delete subscription")
 
-	if err := h.service.DeleteSubscription(c.Context(), id); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+	if err := h.service.DeleteSubscription(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "DeleteSubscription",
 			"id":      id,
@@ -235,7 +239,7 @@ func (h *SubscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":     "DeleteSubscription",
 		"id":          id,
 		"status_code": fiber.StatusOK,
@@ -246,17 +250,23 @@ func (h *SubscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 
 // ListSubscriptions получает список подписок
 // @Summary Список подписок
-// @Description Возвращает список подписок с пагинацией
+// @Description Возвращает список подписок с пагинацией. Фильтр по категории включает подкатегории
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Количество записей на странице" default(10)
+// @Param user_id query string false "ID пользователя"
+// @Param category_id query string false "ID категории"
+// @Param tag query string false "Тег"
+// @Param status query string false "Статус" Enums(trialing, active, paused, cancel_scheduled, cancelled, expired)
+// @Param payment_method_id query string false "ID способа оплаты"
 // @Success 200 {array} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный фильтр"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) ListSubscriptions(c *fiber.Ctx) error {
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "ListSubscriptions",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -267,26 +277,48 @@ func (h *SubscriptionHandler) ListSubscriptions(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
-	logger.Log.WithFields(logrus.Fields{
-		"handler": "ListSubscriptions",
-		"page":    page,
-		"limit":   limit,
+	req := models.ListSubscriptionsRequest{Page: page, Limit: limit}
+	if userID := c.Query("user_id"); userID != "" {
+		req.UserID = &userID
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		req.CategoryID = &categoryID
+	}
+	if tag := c.Query("tag"); tag != "" {
+		req.Tag = &tag
+	}
+	if status := c.Query("status"); status != "" {
+		req.Status = &status
+	}
+	if methodID := c.Query("payment_method_id"); methodID != "" {
+		req.PaymentMethodID = &methodID
+	}
+
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+		"handler":      "ListSubscriptions",
+		"page":         page,
+		"limit":        limit,
+		"has_user":     req.UserID != nil,
+		"has_category": req.CategoryID != nil,
+		"has_tag":      req.Tag != nil,
+		"has_status":   req.Status != nil,
+		"has_method":   req.PaymentMethodID != nil,
 	}).Debug("Query parameters parsed")
 
-	subscriptions, err := h.service.ListSubscriptions(c.Context(), page, limit)
+	subscriptions, err := h.service.ListSubscriptions(c.UserContext(), req)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "ListSubscriptions",
 			"page":    page,
 			"limit":   limit,
 		}).Error("Service failed to list subscriptions")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":     "ListSubscriptions",
 		"count":       len(subscriptions),
 		"status_code": fiber.StatusOK,
@@ -299,17 +331,18 @@ func (h *SubscriptionHandler) ListSubscriptions(c *fiber.Ctx) error {
 
 // GetSummary получает сводку по подпискам
 // @Summary Сводка по подпискам
-// @Description Возвращает общую стоимость подписок за период
+// @Description Возвращает общую стоимость подписок за период: каждый месяц оплачивается по цене активной фазы (пробный период, вводная или обычная цена), месяцы паузы не учитываются. С group_by=category|tag добавляет разбивку по корневым категориям или тегам. С user_id учитывается только доля пользователя, включая общие подписки других пользователей. Также возвращает число использований подписок за период и стоимость одного использования
 // @Tags summary
 // @Accept json
 // @Produce json
 // @Param request body models.SummaryRequest true "Параметры фильтрации"
 // @Success 200 {object} models.SubscriptionSummary
 // @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 429 {object} map[string]string "Превышен лимит запросов"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /subscriptions/summary [post]
 func (h *SubscriptionHandler) GetSummary(c *fiber.Ctx) error {
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler": "GetSummary",
 		"method":  c.Method(),
 		"path":    c.Path(),
@@ -319,7 +352,7 @@ func (h *SubscriptionHandler) GetSummary(c *fiber.Ctx) error {
 	var req models.SummaryRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"handler": "GetSummary",
 		}).Error("Failed to parse request body")
@@ -328,7 +361,7 @@ func (h *SubscriptionHandler) GetSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":          "GetSummary",
 		"start_date":       req.StartDate,
 		"end_date":         req.EndDate,
@@ -336,20 +369,20 @@ func (h *SubscriptionHandler) GetSummary(c *fiber.Ctx) error {
 		"has_service_name": req.ServiceName != nil,
 	}).Debug("Request body parsed successfully")
 
-	summary, err := h.service.GetSummary(c.Context(), req)
+	summary, err := h.service.GetSummary(c.UserContext(), req)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"error":      err.Error(),
 			"handler":    "GetSummary",
 			"start_date": req.StartDate,
 			"end_date":   req.EndDate,
 		}).Error("Service failed to calculate summary")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 		"handler":     "GetSummary",
 		"total_cost":  summary.TotalCost,
 		"status_code": fiber.StatusOK,
@@ -359,3 +392,141 @@ func (h *SubscriptionHandler) GetSummary(c *fiber.Ctx) error {
 
 	return c.JSON(summary)
 }
+
+// CancelSubscription отменяет подписку сразу
+// @Summary Отменить подписку
+// @Description Отменяет подписку немедленно: текущий месяц остается последним оплаченным. Допустимо из статусов trialing, active, paused и cancel_scheduled
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Переход недопустим"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	subscription, err := h.service.CancelSubscription(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CancelSubscription",
+			"id":      id,
+		}).Error("Service failed to cancel subscription")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// CancelAtPeriodEnd планирует отмену подписки
+// @Summary Отменить подписку в конце периода
+// @Description Планирует отмену после указанного месяца (по умолчанию текущего). До этого подписка в статусе cancel_scheduled
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.CancelAtPeriodEndRequest false "Последний оплачиваемый месяц"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Переход недопустим"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/cancel-at-period-end [post]
+func (h *SubscriptionHandler) CancelAtPeriodEnd(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.CancelAtPeriodEndRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+				"error":   err.Error(),
+				"handler": "CancelAtPeriodEnd",
+				"id":      id,
+			}).Error("Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	subscription, err := h.service.CancelAtPeriodEnd(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CancelAtPeriodEnd",
+			"id":      id,
+		}).Error("Service failed to schedule subscription cancellation")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// ReactivateSubscription возобновляет подписку
+// @Summary Возобновить отмененную подписку
+// @Description Снимает запланированную отмену или возобновляет отмененную либо истекшую подписку с текущего месяца. Пропущенные месяцы не начисляются
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 409 {object} map[string]string "Переход недопустим"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/reactivate [post]
+func (h *SubscriptionHandler) ReactivateSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	subscription, err := h.service.ReactivateSubscription(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ReactivateSubscription",
+			"id":      id,
+		}).Error("Service failed to reactivate subscription")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subscription)
+}
+
+// ListTransitions получает историю статусов подписки
+// @Summary История статусов подписки
+// @Description Возвращает переходы подписки между статусами в порядке выполнения
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} models.SubscriptionTransition
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /subscriptions/{id}/transitions [get]
+func (h *SubscriptionHandler) ListTransitions(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	transitions, err := h.service.ListTransitions(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListTransitions",
+			"id":      id,
+		}).Warn("Failed to list subscription transitions")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(transitions)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TagHandler struct {
+	service services.TagService
+}
+
+func NewTagHandler(service services.TagService) *TagHandler {
+	logger.Log.WithField("component", "tag_handler").Info("Creating new tag handler")
+	return &TagHandler{service: service}
+}
+
+// CreateTag создает тег
+// @Summary Создать тег
+// @Description Создает тег. Имена тегов уникальны без учета регистра
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body models.CreateTagRequest true "Данные тега"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Тег уже существует"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tags [post]
+func (h *TagHandler) CreateTag(c *fiber.Ctx) error {
+	var req models.CreateTagRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateTag",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	tag, err := h.service.CreateTag(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateTag",
+		}).Error("Service failed to create tag")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(tag)
+}
+
+// DeleteTag удаляет тег
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех подписок
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "ID тега"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Тег не найден"
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.DeleteTag(c.UserContext(), id); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeleteTag",
+			"id":      id,
+		}).Error("Service failed to delete tag")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "Tag deleted successfully"})
+}
+
+// ListTags получает список тегов
+// @Summary Список тегов
+// @Description Возвращает все теги в алфавитном порядке
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Tag
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tags [get]
+func (h *TagHandler) ListTags(c *fiber.Ctx) error {
+	tags, err := h.service.ListTags(c.UserContext())
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListTags",
+		}).Error("Service failed to list tags")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(tags)
+}
+
+// SetSubscriptionTags заменяет теги подписки
+// @Summary Задать теги подписки
+// @Description Заменяет набор тегов подписки. Отсутствующие теги создаются, пустой список снимает все теги
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.SetSubscriptionTagsRequest true "Теги"
+// @Success 200 {object} models.SetSubscriptionTagsRequest
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/tags [put]
+func (h *TagHandler) SetSubscriptionTags(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.SetSubscriptionTagsRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetSubscriptionTags",
+			"id":      id,
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	tags, err := h.service.SetSubscriptionTags(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "SetSubscriptionTags",
+			"id":      id,
+		}).Error("Service failed to set subscription tags")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(models.SetSubscriptionTagsRequest{Tags: tags})
+}
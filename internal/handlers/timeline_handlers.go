@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TimelineHandler struct {
+	service services.TimelineService
+}
+
+func NewTimelineHandler(service services.TimelineService) *TimelineHandler {
+	logger.Log.WithField("component", "timeline_handler").Info("Creating new timeline handler")
+	return &TimelineHandler{service: service}
+}
+
+// GetTimeline получает хронологию подписок пользователя
+// @Summary Хронология подписок пользователя
+// @Description Возвращает по каждому сервису периоды подписок пользователя (включая общие), их пересечения и разрывы. Для пересечений оценивается переплата по правилам сводки: доля пользователя в списаниях всех подписок за месяцы пересечения за вычетом самой большой из них. Бессрочные подписки учитываются до текущего месяца
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.Timeline
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /users/{id}/timeline [get]
+func (h *TimelineHandler) GetTimeline(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	timeline, err := h.service.GetTimeline(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetTimeline",
+			"id":      id,
+		}).Error("Service failed to build subscription timeline")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(timeline)
+}
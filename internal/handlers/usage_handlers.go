@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"subscribe_project/internal/models"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RecordUsage записывает использование подписки
+// @Summary Записать использование подписки
+// @Description Записывает использование вручную или от интеграции: увеличивает число использований за месяц used_at и обновляет время последнего использования. used_at должен попадать в период подписки и быть не позже отмены. Возвращает использование с историей за 12 месяцев
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body models.RecordUsageRequest false "Время и число использований"
+// @Success 200 {object} models.SubscriptionUsage
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/usage [post]
+func (h *SubscriptionHandler) RecordUsage(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.RecordUsageRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+				"error":   err.Error(),
+				"handler": "RecordUsage",
+				"id":      id,
+			}).Error("Failed to parse request body")
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	usage, err := h.service.RecordUsage(c.UserContext(), id, req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "RecordUsage",
+			"id":      id,
+		}).Error("Service failed to record usage")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(usage)
+}
+
+// GetUsage получает использование подписки
+// @Summary Использование подписки
+// @Description Возвращает время последнего использования, общее число использований и помесячную историю за 12 месяцев со стоимостью одного использования
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} models.SubscriptionUsage
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Подписка не найдена"
+// @Router /subscriptions/{id}/usage [get]
+func (h *SubscriptionHandler) GetUsage(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	usage, err := h.service.GetUsage(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetUsage",
+			"id":      id,
+		}).Warn("Failed to get subscription usage")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(usage)
+}
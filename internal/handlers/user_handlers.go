@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"strconv"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/services"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type UserHandler struct {
+	service services.UserService
+}
+
+func NewUserHandler(service services.UserService) *UserHandler {
+	logger.Log.WithField("component", "user_handler").Info("Creating new user handler")
+	return &UserHandler{service: service}
+}
+
+// CreateUser создает пользователя
+// @Summary Создать пользователя
+// @Description Создает пользователя с именем, email, часовым поясом и настройками
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.CreateUserRequest true "Данные пользователя"
+// @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 409 {object} map[string]string "Email уже используется"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+		"handler": "CreateUser",
+		"method":  c.Method(),
+		"path":    c.Path(),
+	}).Info("Received request to create user")
+
+	var req models.CreateUserRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateUser",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := h.service.CreateUser(c.UserContext(), req)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "CreateUser",
+		}).Error("Service failed to create user")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+// GetUser получает пользователя по ID
+// @Summary Получить пользователя
+// @Description Возвращает пользователя по его ID. Удаленные пользователи не возвращаются
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, err := h.service.GetUser(c.UserContext(), id)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "GetUser",
+		}).Warn("Failed to get user")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(user)
+}
+
+// UpdateUser обновляет пользователя
+// @Summary Обновить пользователя
+// @Description Обновляет имя, email, часовой пояс или настройки. Пустой email удаляет адрес
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body models.UpdateUserRequest true "Данные для обновления"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный запрос"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Failure 409 {object} map[string]string "Email уже используется"
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateUserRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateUser",
+		}).Error("Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.service.UpdateUser(c.UserContext(), id, req); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "UpdateUser",
+		}).Error("Service failed to update user")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "User updated successfully"})
+}
+
+// DeleteUser удаляет пользователя
+// @Summary Удалить пользователя
+// @Description По умолчанию помечает пользователя удаленным, завершает его открытые подписки текущим месяцем, удаляет еще не начавшиеся, исключает его из участников чужих подписок и прекращает проверку его бюджетов. С hard=true удаляет пользователя вместе со всеми подписками
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param hard query bool false "Удалить безвозвратно" default(false)
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Некорректный ID"
+// @Failure 404 {object} map[string]string "Пользователь не найден"
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	hard := c.QueryBool("hard", false)
+
+	if err := h.service.DeleteUser(c.UserContext(), id, hard); err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "DeleteUser",
+			"hard":    hard,
+		}).Error("Service failed to delete user")
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "User deleted successfully"})
+}
+
+// ListUsers получает список пользователей
+// @Summary Список пользователей
+// @Description Возвращает не удаленных пользователей с пагинацией
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество записей на странице" default(10)
+// @Success 200 {array} models.User
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /users [get]
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	users, err := h.service.ListUsers(c.UserContext(), page, limit)
+	if err != nil {
+		logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"handler": "ListUsers",
+		}).Error("Service failed to list users")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(users)
+}
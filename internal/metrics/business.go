@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"subscribe_project/internal/models"
+	"subscribe_project/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ServiceStatsSource interface {
+	GetServiceStats(ctx context.Context) ([]models.ServiceStats, error)
+}
+
+// StartBusinessMetricsRefresher периодически пересчитывает бизнес-метрики
+// до отмены контекста.
+func StartBusinessMetricsRefresher(ctx context.Context, source ServiceStatsSource, interval time.Duration) {
+	refreshBusinessMetrics(ctx, source)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshBusinessMetrics(ctx, source)
+		}
+	}
+}
+
+func refreshBusinessMetrics(ctx context.Context, source ServiceStatsSource) {
+	stats, err := source.GetServiceStats(ctx)
+	if err != nil {
+		logger.Log.WithError(err).Error("Failed to refresh business metrics")
+		return
+	}
+
+	// Сбрасываем значения, чтобы сервисы без активных подписок пропадали из метрик.
+	ActiveSubscriptions.Reset()
+	MonthlySpend.Reset()
+
+	for _, stat := range stats {
+		ActiveSubscriptions.WithLabelValues(stat.ServiceName).Set(float64(stat.ActiveSubscriptions))
+		MonthlySpend.WithLabelValues(stat.ServiceName).Set(float64(stat.MonthlySpend))
+	}
+
+	logger.Log.WithFields(logrus.Fields{
+		"services": len(stats),
+	}).Debug("Business metrics refreshed")
+}
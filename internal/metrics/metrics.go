@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subscription_service"
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RepositoryQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_query_duration_seconds",
+		Help:      "Database query latency by repository method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	ActiveSubscriptions = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_subscriptions",
+		Help:      "Number of subscriptions active in the current month by service.",
+	}, []string{"service_name"})
+
+	MonthlySpend = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "monthly_spend",
+		Help:      "Total monthly price of active subscriptions by service.",
+	}, []string{"service_name"})
+
+	SummaryReadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "summary_reads_total",
+		Help:      "Summary calculations by source: precomputed rollups or raw subscriptions.",
+	}, []string{"source"})
+
+	CacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cached endpoint and result: hit, miss or error.",
+	}, []string{"cache", "result"})
+)
+
+// ObserveQuery записывает длительность запроса репозитория. Используется через defer:
+//
+//	defer metrics.ObserveQuery("subscription", "GetByID", time.Now())
+func ObserveQuery(repository, method string, start time.Time) {
+	RepositoryQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+// RegisterDBStats публикует статистику пула соединений: открытые, занятые,
+// количество и длительность ожиданий.
+func RegisterDBStats(db *sql.DB, dbName string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
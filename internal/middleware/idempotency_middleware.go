@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"subscribe_project/internal/repository"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// IdempotencyMiddleware сохраняет ответы на POST-запросы с заголовком
+// Idempotency-Key и повторяет их при ретраях клиента в течение ttl. Ключ
+// действует в пределах клиента, метода и пути: разные клиенты с одинаковым
+// ключом не получают ответы друг друга.
+func IdempotencyMiddleware(repo repository.IdempotencyRepository, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodPost {
+			return c.Next()
+		}
+
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return c.Next()
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Idempotency-Key is too long",
+			})
+		}
+
+		requestHash := fingerprint(c)
+		storageKey := scopedIdempotencyKey(c, key)
+		keyLogger := logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"idempotency_key": key,
+			"method":          c.Method(),
+			"path":            c.Path(),
+		})
+
+		record, reserved, err := repo.Reserve(c.UserContext(), storageKey, requestHash, time.Now().Add(ttl))
+		if err != nil {
+			keyLogger.WithError(err).Error("Failed to reserve idempotency key")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		if !reserved {
+			if record.RequestHash != requestHash {
+				keyLogger.Warn("Idempotency key reused with different request")
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"error": "Idempotency-Key has already been used with a different request",
+				})
+			}
+
+			if !record.Completed() {
+				keyLogger.Warn("Request with the same idempotency key is still in progress")
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error": "Request with this Idempotency-Key is still being processed",
+				})
+			}
+
+			keyLogger.WithField("status", *record.StatusCode).Info("Replaying stored response for idempotency key")
+			if record.ContentType != nil {
+				c.Set(fiber.HeaderContentType, *record.ContentType)
+			}
+			c.Set(IdempotentReplayedHeader, "true")
+			return c.Status(*record.StatusCode).Send(record.ResponseBody)
+		}
+
+		err = c.Next()
+		if err != nil {
+			// Ответ еще не сформирован ErrorHandler'ом, поэтому ключ освобождается,
+			// чтобы клиент мог повторить запрос.
+			if releaseErr := repo.Release(c.UserContext(), storageKey); releaseErr != nil {
+				keyLogger.WithError(releaseErr).Error("Failed to release idempotency key")
+			}
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			if releaseErr := repo.Release(c.UserContext(), storageKey); releaseErr != nil {
+				keyLogger.WithError(releaseErr).Error("Failed to release idempotency key")
+			}
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if completeErr := repo.Complete(c.UserContext(), storageKey, status, body, contentType); completeErr != nil {
+			keyLogger.WithError(completeErr).Error("Failed to store response for idempotency key")
+			return nil
+		}
+
+		keyLogger.WithField("status", status).Debug("Stored response for idempotency key")
+		return nil
+	}
+}
+
+// scopedIdempotencyKey привязывает ключ клиента к его наиболее точной
+// идентификации из clientKeys (API-ключ, пользователь или IP), методу и пути.
+// Результат хешируется, чтобы уложиться в длину колонки.
+func scopedIdempotencyKey(c *fiber.Ctx, key string) string {
+	keys := clientKeys(c)
+	hash := sha256.New()
+	hash.Write([]byte(keys[len(keys)-1]))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Path()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func fingerprint(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Path()))
+	hash.Write([]byte{0})
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -13,12 +13,11 @@ func LoggerMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
 
-		requestLogger := logger.Log.WithFields(logrus.Fields{
+		requestLogger := logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 			"method":     c.Method(),
 			"path":       c.Path(),
 			"ip":         c.IP(),
 			"user_agent": c.Get("User-Agent"),
-			"request_id": c.Get("X-Request-ID"),
 			"start_time": start.Format(time.RFC3339),
 		})
 
@@ -38,10 +37,6 @@ func LoggerMiddleware() fiber.Handler {
 			"duration":    duration.String(),
 		}
 
-		if requestID := c.Get("X-Request-ID"); requestID != "" {
-			fields["request_id"] = requestID
-		}
-
 		status := c.Response().StatusCode()
 		switch {
 		case status >= 500:
@@ -61,7 +56,7 @@ func LoggerMiddleware() fiber.Handler {
 		}
 
 		if err != nil {
-			logger.Log.WithFields(logrus.Fields{
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
 				"error":       err.Error(),
 				"method":      c.Method(),
 				"path":        c.Path(),
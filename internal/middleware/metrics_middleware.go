@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"subscribe_project/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const unmatchedRoute = "unmatched"
+
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Статус еще не выставлен ErrorHandler'ом, определяем его по ошибке.
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		// Используем шаблон маршрута, а не фактический путь, чтобы не плодить метки.
+		route := c.Route().Path
+		if c.Route().Method == "USE" {
+			route = unmatchedRoute
+		}
+
+		labels := []string{c.Method(), route, strconv.Itoa(status)}
+		metrics.HTTPRequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
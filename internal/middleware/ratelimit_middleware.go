@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+
+	"subscribe_project/internal/ratelimit"
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	UserIDHeader = "X-User-ID"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов клиента по политике
+// маршрута. Заголовки X-API-Key и X-User-ID не аутентифицируются, поэтому
+// запрос всегда списывается из корзины IP, а при наличии заголовка — еще и
+// из корзины ключа или пользователя: случайный ключ на каждый запрос не
+// обходит лимит. При недоступности хранилища запрос пропускается.
+func RateLimitMiddleware(store ratelimit.Store, policies ratelimit.Policies) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy := policies.Match(c.Method(), c.Path())
+
+		var result ratelimit.Result
+		var client string
+		for i, key := range clientKeys(c) {
+			taken, err := store.Take(c.UserContext(), policy.Name+"|"+key, policy)
+			if err != nil {
+				logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+					"error":  err.Error(),
+					"policy": policy.Name,
+				}).Error("Rate limit store failed, allowing request")
+				return c.Next()
+			}
+			// Заголовки отражают самую исчерпанную из корзин клиента.
+			if i == 0 || !taken.Allowed || taken.Remaining < result.Remaining {
+				result, client = taken, key
+			}
+			if !taken.Allowed {
+				break
+			}
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset.Seconds())))
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d",
+			policy.Requests, ceilSeconds(policy.Period.Seconds()), policy.Capacity()))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter.Seconds())
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+			logger.FromContext(c.UserContext()).WithFields(logrus.Fields{
+				"policy":      policy.Name,
+				"client":      client,
+				"retry_after": retryAfter,
+			}).Warn("Rate limit exceeded")
+
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many requests",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// clientKeys возвращает корзины клиента: всегда IP и, если передан,
+// API-ключ или пользователя. API-ключ хешируется, чтобы не хранить его в
+// открытом виде.
+func clientKeys(c *fiber.Ctx) []string {
+	keys := []string{"ip:" + c.IP()}
+	if apiKey := c.Get(APIKeyHeader); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return append(keys, "api_key:"+hex.EncodeToString(sum[:8]))
+	}
+	if userID, err := uuid.Parse(c.Get(UserIDHeader)); err == nil {
+		return append(keys, "user:"+userID.String())
+	}
+	return keys
+}
+
+func ceilSeconds(seconds float64) int {
+	return int(math.Ceil(math.Max(0, seconds)))
+}
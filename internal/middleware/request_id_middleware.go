@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"subscribe_project/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+	requestIDLocalsKey = "request_id"
+)
+
+// RequestIDMiddleware берет X-Request-ID из запроса или генерирует новый,
+// возвращает его в ответе и кладет в контекст вместе с логгером запроса.
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(RequestIDHeader, requestID)
+		c.Locals(requestIDLocalsKey, requestID)
+
+		ctx := logger.WithRequestID(c.UserContext(), requestID)
+		ctx = logger.WithEntry(ctx, logger.Log.WithField("request_id", requestID))
+		c.SetUserContext(ctx)
+
+		return c.Next()
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
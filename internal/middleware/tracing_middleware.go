@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"subscribe_project/internal/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware открывает серверный спан на каждый запрос, продолжая
+// трассу из заголовка traceparent, и возвращает traceparent в ответе.
+func TracingMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(c.UserContext(), requestCarrier{c: c})
+
+		ctx, span := tracing.Tracer().Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		propagator.Inject(ctx, responseCarrier{c: c})
+
+		err := c.Next()
+
+		if route := c.Route(); route.Method != "USE" {
+			span.SetName(c.Method() + " " + route.Path)
+			span.SetAttributes(semconv.HTTPRoute(route.Path))
+		}
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			span.RecordError(err)
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fiber.ErrInternalServerError.Message)
+		}
+
+		return err
+	}
+}
+
+type requestCarrier struct {
+	c *fiber.Ctx
+}
+
+func (rc requestCarrier) Get(key string) string {
+	return rc.c.Get(key)
+}
+
+func (rc requestCarrier) Set(key, value string) {
+	rc.c.Request().Header.Set(key, value)
+}
+
+func (rc requestCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range rc.c.GetReqHeaders() {
+		keys = append(keys, strings.ToLower(key))
+	}
+	return keys
+}
+
+type responseCarrier struct {
+	c *fiber.Ctx
+}
+
+func (rc responseCarrier) Get(key string) string {
+	return rc.c.GetRespHeader(key)
+}
+
+func (rc responseCarrier) Set(key, value string) {
+	rc.c.Set(key, value)
+}
+
+func (rc responseCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range rc.c.GetRespHeaders() {
+		keys = append(keys, strings.ToLower(key))
+	}
+	return keys
+}
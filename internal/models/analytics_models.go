@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AnalyticsRequest struct {
+	StartDate   *string
+	EndDate     *string
+	ServiceName *string
+}
+
+type AnalyticsFilter struct {
+	From        time.Time
+	To          time.Time
+	ServiceName *string
+	ServiceID   *uuid.UUID
+}
+
+// ServiceMonthMetrics — показатели сервиса за месяц. Подписка считается
+// отмененной в месяце end_date (последнем оплаченном месяце).
+type ServiceMonthMetrics struct {
+	Month       time.Time `db:"month"`
+	ServiceName string    `db:"service_name"`
+	Active      int       `db:"active"`
+	MRR         int       `db:"mrr"`
+	New         int       `db:"new"`
+	Cancelled   int       `db:"cancelled"`
+}
+
+// MRRPoint — месячная регулярная выручка: цена активных подписок,
+// приведенная к месяцу (цена фазы или price / интервал оплаты в месяцах).
+// Месяцы на паузе не учитываются.
+type MRRPoint struct {
+	Month               time.Time `json:"month"`
+	MRR                 int       `json:"mrr"`
+	ActiveSubscriptions int       `json:"active_subscriptions"`
+}
+
+type SubscriptionFlowPoint struct {
+	Month     time.Time `json:"month"`
+	New       int       `json:"new"`
+	Cancelled int       `json:"cancelled"`
+	Net       int       `json:"net"`
+}
+
+// ChurnPoint — доля подписок сервиса, активных в месяце, которые в нем
+// закончились (в процентах).
+type ChurnPoint struct {
+	Month     time.Time `json:"month"`
+	Active    int       `json:"active"`
+	Cancelled int       `json:"cancelled"`
+	ChurnRate float64   `json:"churn_rate"`
+}
+
+type ServiceChurn struct {
+	ServiceName string       `json:"service_name"`
+	ChurnRate   float64      `json:"churn_rate"`
+	Points      []ChurnPoint `json:"points"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Области действия бюджета.
+const (
+	BudgetScopeUser     = "user"
+	BudgetScopeCategory = "category"
+	BudgetScopeService  = "service"
+)
+
+const (
+	BudgetPeriodMonthly = "monthly"
+	BudgetPeriodYearly  = "yearly"
+)
+
+// Состояния бюджета по фактическим расходам за период.
+const (
+	BudgetStateOK       = "ok"
+	BudgetStateWarning  = "warning"
+	BudgetStateExceeded = "exceeded"
+)
+
+// Budget ограничивает долю пользователя в расходах на подписки за период.
+type Budget struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Scope       string     `json:"scope" db:"scope"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty" db:"category_id"`
+	ServiceName *string    `json:"service_name,omitempty" db:"service_name"`
+	Period      string     `json:"period" db:"period"`
+	Amount      int        `json:"amount" db:"amount"`
+	Currency    string     `json:"currency" db:"currency"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateBudgetRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid4"`
+	Scope  string `json:"scope" validate:"required,oneof=user category service"`
+	// CategoryID обязателен для scope = category.
+	CategoryID *string `json:"category_id,omitempty" validate:"omitempty,uuid4"`
+	// ServiceName обязателен для scope = service.
+	ServiceName *string `json:"service_name,omitempty"`
+	Period      string  `json:"period" validate:"required,oneof=monthly yearly"`
+	Amount      int     `json:"amount" validate:"required,min=1"`
+	Currency    *string `json:"currency,omitempty" validate:"omitempty,len=3"`
+}
+
+type UpdateBudgetRequest struct {
+	Period *string `json:"period,omitempty" validate:"omitempty,oneof=monthly yearly"`
+	Amount *int    `json:"amount,omitempty" validate:"omitempty,min=1"`
+}
+
+// BudgetStatus — расходы по бюджету за текущий период. Spent считается по
+// текущий месяц включительно, Projected — до конца периода по действующим
+// подпискам.
+type BudgetStatus struct {
+	Budget           Budget    `json:"budget"`
+	PeriodStart      time.Time `json:"period_start"`
+	PeriodEnd        time.Time `json:"period_end"`
+	Spent            int       `json:"spent"`
+	Projected        int       `json:"projected"`
+	SpentPercent     float64   `json:"spent_percent"`
+	ProjectedPercent float64   `json:"projected_percent"`
+	State            string    `json:"state"`
+}
+
+// BudgetAlert — отправленное уведомление о достижении порога бюджета.
+type BudgetAlert struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	BudgetID    uuid.UUID `json:"budget_id" db:"budget_id"`
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	Threshold   int       `json:"threshold" db:"threshold"`
+	Spent       int       `json:"spent" db:"spent"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
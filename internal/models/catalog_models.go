@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Service — запись каталога сервисов с каноническим именем и алиасами,
+// по которым к нему привязываются подписки.
+type Service struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	Name         string         `json:"name" db:"name"`
+	Aliases      pq.StringArray `json:"aliases" db:"aliases" swaggertype:"array,string"`
+	Category     *string        `json:"category,omitempty" db:"category"`
+	Website      *string        `json:"website,omitempty" db:"website"`
+	DefaultPrice *int           `json:"default_price,omitempty" db:"default_price"`
+	// YearlyPrice — цена годового плана, если сервис его предлагает.
+	YearlyPrice *int      `json:"yearly_price,omitempty" db:"yearly_price"`
+	LogoURL     *string   `json:"logo_url,omitempty" db:"logo_url"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateServiceRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Category     *string  `json:"category,omitempty" validate:"omitempty,max=100"`
+	Website      *string  `json:"website,omitempty" validate:"omitempty,url"`
+	DefaultPrice *int     `json:"default_price,omitempty" validate:"omitempty,min=0"`
+	YearlyPrice  *int     `json:"yearly_price,omitempty" validate:"omitempty,min=0"`
+	LogoURL      *string  `json:"logo_url,omitempty" validate:"omitempty,url"`
+}
+
+type UpdateServiceRequest struct {
+	Name         *string   `json:"name,omitempty" validate:"omitempty,max=100"`
+	Aliases      *[]string `json:"aliases,omitempty"`
+	Category     *string   `json:"category,omitempty" validate:"omitempty,max=100"`
+	Website      *string   `json:"website,omitempty" validate:"omitempty,url"`
+	DefaultPrice *int      `json:"default_price,omitempty" validate:"omitempty,min=0"`
+	YearlyPrice  *int      `json:"yearly_price,omitempty" validate:"omitempty,min=0"`
+	LogoURL      *string   `json:"logo_url,omitempty" validate:"omitempty,url"`
+}
+
+// NormalizeServiceName приводит название к виду, в котором хранятся алиасы:
+// нижний регистр и одиночные пробелы.
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
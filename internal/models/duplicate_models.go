@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Реакция на возможный дубликат при создании подписки.
+const (
+	DuplicatesOff    = "off"
+	DuplicatesWarn   = "warn"
+	DuplicatesReject = "reject"
+)
+
+// DuplicatePair — две подписки одного пользователя на один сервис с
+// пересекающимися периодами. OverlapEnd пуст, если обе бессрочные.
+type DuplicatePair struct {
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	ServiceName    string     `json:"service_name" db:"service_name"`
+	SubscriptionID uuid.UUID  `json:"subscription_id" db:"subscription_id"`
+	DuplicateID    uuid.UUID  `json:"duplicate_id" db:"duplicate_id"`
+	OverlapStart   time.Time  `json:"overlap_start" db:"overlap_start"`
+	OverlapEnd     *time.Time `json:"overlap_end,omitempty" db:"overlap_end"`
+}
+
+type MergeSubscriptionsRequest struct {
+	// KeepID — подписка, которая остается; MergeID поглощается и удаляется.
+	KeepID  string `json:"keep_id" validate:"required,uuid4"`
+	MergeID string `json:"merge_id" validate:"required,uuid4"`
+}
+
+type SubscriptionMerge struct {
+	ID                   uuid.UUID      `json:"id" db:"id"`
+	SubscriptionID       uuid.UUID      `json:"subscription_id" db:"subscription_id"`
+	MergedSubscriptionID uuid.UUID      `json:"merged_subscription_id" db:"merged_subscription_id"`
+	MergedSnapshot       types.JSONText `json:"merged_snapshot" db:"merged_snapshot" swaggertype:"object"`
+	CreatedAt            time.Time      `json:"created_at" db:"created_at"`
+}
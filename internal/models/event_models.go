@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы предстоящих событий по подпискам.
+const (
+	EventTrialEnd = "trial_end"
+	EventIntroEnd = "intro_end"
+	// EventPaymentMethodExpiry — способ оплаты истечет до ближайшего списания.
+	EventPaymentMethodExpiry = "payment_method_expiry"
+)
+
+type UpcomingEvent struct {
+	Type           string    `json:"type" db:"type"`
+	Date           time.Time `json:"date" db:"date"`
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	ServiceName    string    `json:"service_name" db:"service_name"`
+	// Price — цена, которая начнет действовать после события; для
+	// payment_method_expiry — сумма списания, которое не пройдет.
+	Price *int `json:"price,omitempty" db:"price"`
+}
+
+type UpcomingEventsRequest struct {
+	Days   int
+	UserID *string
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type ForecastRequest struct {
+	Months      int
+	UserID      *string
+	ServiceName *string
+}
+
+// ForecastRow — прогнозная стоимость сервиса за один месяц.
+type ForecastRow struct {
+	Month       time.Time `db:"month"`
+	ServiceName string    `db:"service_name"`
+	Cost        int       `db:"cost"`
+}
+
+type ForecastServiceCost struct {
+	ServiceName string `json:"service_name"`
+	Cost        int    `json:"cost"`
+}
+
+// ForecastMonth — точка помесячного ряда прогноза. Месяцы без списаний
+// присутствуют с нулевой стоимостью.
+type ForecastMonth struct {
+	Month     time.Time             `json:"month"`
+	TotalCost int                   `json:"total_cost"`
+	Services  []ForecastServiceCost `json:"services"`
+}
+
+type Forecast struct {
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	TotalCost int             `json:"total_cost"`
+	Months    []ForecastMonth `json:"months"`
+}
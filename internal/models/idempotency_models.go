@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type IdempotencyRecord struct {
+	Key          string    `db:"idempotency_key"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   *int      `db:"status_code"`
+	ResponseBody []byte    `db:"response_body"`
+	ContentType  *string   `db:"content_type"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+// Completed сообщает, сохранен ли уже ответ для ключа.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != nil
+}
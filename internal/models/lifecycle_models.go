@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionTransition — запись истории переходов между статусами.
+type SubscriptionTransition struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	FromStatus     string    `json:"from_status" db:"from_status"`
+	ToStatus       string    `json:"to_status" db:"to_status"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// StateChange — изменение хранимого состояния подписки. Применяется, только
+// если состояние и end_date не изменились с момента чтения (FromState, FromEndDate).
+type StateChange struct {
+	FromState   string
+	FromEndDate *time.Time
+	State       string
+	EndDate     *time.Time
+	// ResumeFrom задается при возобновлении завершенной подписки: месяцы
+	// после прежней end_date и до ResumeFrom не оплачиваются.
+	ResumeFrom *time.Time
+	Transition SubscriptionTransition
+}
+
+type CancelAtPeriodEndRequest struct {
+	// EndDate — последний оплачиваемый месяц, по умолчанию текущий.
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty,datetime=01-2006"`
+}
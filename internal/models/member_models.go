@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Правила разделения стоимости общей подписки.
+const (
+	SplitRuleEqual      = "equal"
+	SplitRulePercentage = "percentage"
+	SplitRuleFixed      = "fixed"
+)
+
+// Роли пользователя в общей подписке.
+const (
+	MemberRoleOwner  = "owner"
+	MemberRoleMember = "member"
+)
+
+type SubscriptionMember struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	SharePercent   *float64  `json:"share_percent,omitempty" db:"share_percent"`
+	ShareAmount    *int      `json:"share_amount,omitempty" db:"share_amount"`
+}
+
+// MemberShare — доля пользователя в стоимости подписки. Владелец получает
+// остаток после долей участников.
+type MemberShare struct {
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	Role         string    `json:"role" db:"role"`
+	SharePercent *float64  `json:"share_percent,omitempty" db:"share_percent"`
+	ShareAmount  *int      `json:"share_amount,omitempty" db:"share_amount"`
+	ShareRatio   float64   `json:"share_ratio" db:"share_ratio"`
+	MonthlyCost  float64   `json:"monthly_cost" db:"monthly_cost"`
+}
+
+type SubscriptionMembers struct {
+	SubscriptionID uuid.UUID     `json:"subscription_id"`
+	SplitRule      string        `json:"split_rule"`
+	Shares         []MemberShare `json:"shares"`
+}
+
+type MemberRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid4"`
+	// SharePercent обязателен для правила percentage.
+	SharePercent *float64 `json:"share_percent,omitempty"`
+	// ShareAmount обязателен для правила fixed.
+	ShareAmount *int `json:"share_amount,omitempty"`
+}
+
+type SetMembersRequest struct {
+	SplitRule string          `json:"split_rule" validate:"required,oneof=equal percentage fixed"`
+	Members   []MemberRequest `json:"members"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionPause — интервал паузы в месяцах. EndDate — месяц
+// возобновления (не включается); пустой, если дата возобновления не задана.
+type SubscriptionPause struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id" db:"subscription_id"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        *time.Time `json:"end_date,omitempty" db:"end_date"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type PauseSubscriptionRequest struct {
+	// StartDate — первый месяц паузы, по умолчанию текущий.
+	StartDate *string `json:"start_date,omitempty" validate:"omitempty,datetime=01-2006"`
+	// ResumeDate — месяц возобновления оплаты; без него пауза бессрочная.
+	ResumeDate *string `json:"resume_date,omitempty" validate:"omitempty,datetime=01-2006"`
+}
+
+type ResumeSubscriptionRequest struct {
+	// ResumeDate — месяц возобновления оплаты, по умолчанию текущий.
+	ResumeDate *string `json:"resume_date,omitempty" validate:"omitempty,datetime=01-2006"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы способов оплаты.
+const (
+	PaymentMethodCard        = "card"
+	PaymentMethodBankAccount = "bank_account"
+	PaymentMethodWallet      = "wallet"
+)
+
+type PaymentMethod struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	Type   string    `json:"type" db:"type"`
+	// Label — маскированное название, например "Visa •••• 4242".
+	Label string `json:"label" db:"label"`
+	// ExpiryMonth — последний месяц действия; пустой для бессрочных способов.
+	ExpiryMonth *time.Time `json:"expiry_month,omitempty" db:"expiry_month"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreatePaymentMethodRequest struct {
+	Type        string  `json:"type" validate:"required,oneof=card bank_account wallet"`
+	Label       string  `json:"label" validate:"required,max=100" example:"Visa •••• 4242"`
+	ExpiryMonth *string `json:"expiry_month,omitempty" validate:"omitempty,datetime=01-2006" example:"08-2027"`
+}
+
+type UpdatePaymentMethodRequest struct {
+	Label *string `json:"label,omitempty" validate:"omitempty,max=100"`
+	// ExpiryMonth: пустая строка снимает срок действия.
+	ExpiryMonth *string `json:"expiry_month,omitempty" example:"08-2027"`
+
+	ExpiryDate *time.Time `json:"-"`
+}
+
+// ExpiringPaymentMethod — подписка, способ оплаты которой истечет до
+// ближайшего платного списания.
+type ExpiringPaymentMethod struct {
+	SubscriptionID  uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	ServiceName     string    `json:"service_name" db:"service_name"`
+	UserID          uuid.UUID `json:"user_id" db:"user_id"`
+	PaymentMethodID uuid.UUID `json:"payment_method_id" db:"payment_method_id"`
+	Label           string    `json:"label" db:"label"`
+	ExpiryMonth     time.Time `json:"expiry_month" db:"expiry_month"`
+	NextBillingDate time.Time `json:"next_billing_date" db:"next_billing_date"`
+	Amount          int       `json:"amount" db:"amount"`
+}
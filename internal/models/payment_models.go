@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCurrency — валюта цен подписок; платежи в других валютах не
+// сопоставляются с ожидаемыми списаниями.
+const DefaultCurrency = "RUB"
+
+type Payment struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	Amount         int       `json:"amount" db:"amount"`
+	Currency       string    `json:"currency" db:"currency"`
+	PaidAt         time.Time `json:"paid_at" db:"paid_at"`
+	PaymentMethod  *string   `json:"payment_method,omitempty" db:"payment_method"`
+	// Reference — идентификатор операции у банка или платежной системы.
+	Reference *string   `json:"reference,omitempty" db:"reference"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreatePaymentRequest struct {
+	SubscriptionID string  `json:"subscription_id" validate:"required,uuid4"`
+	Amount         int     `json:"amount" validate:"required,min=1"`
+	Currency       *string `json:"currency,omitempty" validate:"omitempty,len=3"`
+	PaidAt         string  `json:"paid_at" validate:"required,datetime=2006-01-02"`
+	PaymentMethod  *string `json:"payment_method,omitempty"`
+	Reference      *string `json:"reference,omitempty"`
+}
+
+type ListPaymentsRequest struct {
+	Page           int
+	Limit          int
+	SubscriptionID *string
+	UserID         *string
+	// From и To — границы даты платежа в формате YYYY-MM-DD включительно.
+	From *string
+	To   *string
+}
+
+type PaymentFilter struct {
+	Limit          int
+	Offset         int
+	SubscriptionID *uuid.UUID
+	UserID         *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+}
+
+// Результаты сверки месяца подписки.
+const (
+	ReconciliationOK         = "ok"
+	ReconciliationMissing    = "missing"
+	ReconciliationDuplicate  = "duplicate"
+	ReconciliationMismatch   = "mismatch"
+	ReconciliationUnexpected = "unexpected"
+)
+
+type ReconciliationRequest struct {
+	StartDate      string
+	EndDate        string
+	UserID         *string
+	SubscriptionID *string
+}
+
+type ReconciliationFilter struct {
+	From           time.Time
+	To             time.Time
+	UserID         *uuid.UUID
+	SubscriptionID *uuid.UUID
+}
+
+// ReconciliationItem сравнивает ожидаемое списание за месяц с платежами,
+// записанными в этом месяце.
+type ReconciliationItem struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" db:"subscription_id"`
+	ServiceName    string    `json:"service_name" db:"service_name"`
+	Month          time.Time `json:"month" db:"month"`
+	ExpectedAmount int       `json:"expected_amount" db:"expected_amount"`
+	PaidAmount     int       `json:"paid_amount" db:"paid_amount"`
+	Payments       int       `json:"payments" db:"payments"`
+	// ForeignPayments — платежи в валюте, отличной от DefaultCurrency;
+	// они не входят в PaidAmount.
+	ForeignPayments int    `json:"foreign_payments" db:"foreign_payments"`
+	Status          string `json:"status" db:"-"`
+}
+
+type ReconciliationReport struct {
+	TotalExpected int                  `json:"total_expected"`
+	TotalPaid     int                  `json:"total_paid"`
+	Issues        int                  `json:"issues"`
+	Items         []ReconciliationItem `json:"items"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы фаз подписки. Фаза regular не хранится: она начинается после
+// последней начальной фазы и использует цену подписки.
+const (
+	PhaseTrial   = "trial"
+	PhaseIntro   = "intro"
+	PhaseRegular = "regular"
+)
+
+type SubscriptionPhase struct {
+	ID             uuid.UUID `json:"-" db:"id"`
+	SubscriptionID uuid.UUID `json:"-" db:"subscription_id"`
+	Sequence       int       `json:"sequence" db:"sequence"`
+	PhaseType      string    `json:"phase_type" db:"phase_type"`
+	DurationMonths *int      `json:"duration_months,omitempty" db:"duration_months"`
+	Price          int       `json:"price" db:"price"`
+	StartDate      time.Time `json:"start_date" db:"start_date"`
+	// EndDate — первый месяц следующей фазы; пустой у фазы regular.
+	EndDate *time.Time `json:"end_date,omitempty" db:"end_date"`
+}
+
+type PhaseRequest struct {
+	PhaseType      string `json:"phase_type" validate:"required,oneof=trial intro"`
+	DurationMonths int    `json:"duration_months" validate:"required,min=1"`
+	// Price для пробного периода можно не указывать, он бесплатный.
+	Price int `json:"price" validate:"min=0"`
+}
+
+type SetPhasesRequest struct {
+	Phases []PhaseRequest `json:"phases"`
+}
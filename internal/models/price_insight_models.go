@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PriceAnomalyOutlier         = "outlier"
+	PriceAnomalyIncrease        = "increase"
+	PriceAnomalyServiceIncrease = "service_increase"
+)
+
+// PriceInsightRules — пороги поиска аномалий цен.
+type PriceInsightRules struct {
+	// IncreaseThreshold — минимальный относительный рост цены (0.2 = 20%).
+	IncreaseThreshold float64
+	// MinSample — минимальное число действующих подписок сервиса для поиска выбросов.
+	MinSample int
+	// MinIncreaseUsers — число пользователей с повышением цены, после которого
+	// повышение считается повышением цены самого сервиса.
+	MinIncreaseUsers int
+	// Since — начало окна, в котором учитываются изменения цены.
+	Since time.Time
+}
+
+// ServicePriceStats — распределение месячной цены действующих подписок сервиса.
+type ServicePriceStats struct {
+	ServiceName   string    `json:"service_name" db:"service_name"`
+	Subscriptions int       `json:"subscriptions" db:"subscriptions"`
+	MinPrice      float64   `json:"min_price" db:"min_price"`
+	P25Price      float64   `json:"p25_price" db:"p25_price"`
+	MedianPrice   float64   `json:"median_price" db:"median_price"`
+	P75Price      float64   `json:"p75_price" db:"p75_price"`
+	MaxPrice      float64   `json:"max_price" db:"max_price"`
+	ComputedAt    time.Time `json:"computed_at" db:"computed_at"`
+}
+
+// PriceAnomaly — найденная аномалия. Цены приведены к месяцу по интервалу
+// оплаты. Для outlier baseline — медианная месячная цена сервиса, для
+// increase — прежняя цена подписки, для service_increase — средние цены до и
+// после повышения у affected пользователей.
+type PriceAnomaly struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Kind           string     `json:"kind" db:"kind"`
+	ServiceName    string     `json:"service_name" db:"service_name"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" db:"subscription_id"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Price          float64    `json:"price" db:"price"`
+	Baseline       float64    `json:"baseline" db:"baseline"`
+	Ratio          float64    `json:"ratio" db:"ratio"`
+	Affected       int        `json:"affected" db:"affected"`
+	ObservedAt     time.Time  `json:"observed_at" db:"observed_at"`
+	DetectedAt     time.Time  `json:"detected_at" db:"detected_at"`
+}
+
+type PriceInsightsRequest struct {
+	ServiceName *string
+	UserID      *string
+	Kind        *string
+}
+
+// PriceAnomalyFilter — фильтр аномалий для репозитория. Пользователь видит
+// аномалии своих подписок и повышения цен сервисов, на которые подписан.
+type PriceAnomalyFilter struct {
+	ServiceName *string
+	UserID      *uuid.UUID
+	Kind        *string
+}
+
+type PriceInsights struct {
+	ComputedAt *time.Time          `json:"computed_at,omitempty"`
+	Services   []ServicePriceStats `json:"services"`
+	Anomalies  []PriceAnomaly      `json:"anomalies"`
+}
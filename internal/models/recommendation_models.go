@@ -0,0 +1,48 @@
+package models
+
+import "github.com/google/uuid"
+
+// Виды рекомендаций по экономии.
+const (
+	RecommendationYearlyPlan      = "yearly_plan"
+	RecommendationCategoryOverlap = "category_overlap"
+	RecommendationUnused          = "unused"
+)
+
+// RecommendationCandidate — действующая подписка пользователя с данными,
+// нужными для рекомендаций. CategoryKey — категория подписки, а если она не
+// задана — категория сервиса из каталога. Idle — использование
+// отслеживается, но подпиской не пользовались 30 дней.
+type RecommendationCandidate struct {
+	ID             uuid.UUID `db:"id"`
+	ServiceName    string    `db:"service_name"`
+	Price          int       `db:"price"`
+	IntervalMonths int       `db:"interval_months"`
+	ShareRatio     float64   `db:"share_ratio"`
+	Unused         bool      `db:"unused"`
+	Idle           bool      `db:"idle"`
+	CategoryKey    *string   `db:"category_key"`
+	CategoryName   *string   `db:"category_name"`
+	YearlyPrice    *int      `db:"yearly_price"`
+}
+
+// Recommendation — совет по одной подписке. Стоимость и экономия считаются
+// по обычной цене и доле пользователя, как в сводке.
+type Recommendation struct {
+	Kind              string      `json:"kind"`
+	SubscriptionID    uuid.UUID   `json:"subscription_id"`
+	ServiceName       string      `json:"service_name"`
+	Message           string      `json:"message"`
+	CurrentYearlyCost int         `json:"current_yearly_cost"`
+	MonthlySavings    int         `json:"monthly_savings"`
+	YearlySavings     int         `json:"yearly_savings"`
+	Related           []uuid.UUID `json:"related_subscription_ids,omitempty"`
+}
+
+// Recommendations — рекомендации пользователя. YearlySavings учитывает
+// каждую подписку один раз, по самой выгодной рекомендации для нее.
+type Recommendations struct {
+	UserID          uuid.UUID        `json:"user_id"`
+	Recommendations []Recommendation `json:"recommendations"`
+	YearlySavings   int              `json:"yearly_savings"`
+}
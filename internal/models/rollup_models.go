@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RollupState — окно месяцев, за которое построены агрегаты расходов.
+type RollupState struct {
+	CoveredFrom time.Time `json:"covered_from" db:"covered_from"`
+	CoveredTo   time.Time `json:"covered_to" db:"covered_to"`
+	RebuiltAt   time.Time `json:"rebuilt_at" db:"rebuilt_at"`
+	RefreshedAt time.Time `json:"refreshed_at" db:"refreshed_at"`
+}
+
+// RollupMismatch — расхождение агрегата с расчетом по подпискам. Пустые
+// значения означают, что строки нет с одной из сторон.
+type RollupMismatch struct {
+	Month       time.Time  `json:"month" db:"month"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	ServiceName string     `json:"service_name" db:"service_name"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty" db:"service_id"`
+	RollupShare *float64   `json:"rollup_share_cost" db:"rollup_share_cost"`
+	RawShare    *float64   `json:"raw_share_cost" db:"raw_share_cost"`
+	RollupOwned *int       `json:"rollup_owned_cost" db:"rollup_owned_cost"`
+	RawOwned    *int       `json:"raw_owned_cost" db:"raw_owned_cost"`
+	RollupCount *int       `json:"rollup_subscriptions" db:"rollup_subscriptions"`
+	RawCount    *int       `json:"raw_subscriptions" db:"raw_subscriptions"`
+}
@@ -4,40 +4,160 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Subscription struct {
 	ID          uuid.UUID  `json:"id" db:"id"`
 	ServiceName string     `json:"service_name" db:"service_name" validate:"required"`
+	ServiceID   *uuid.UUID `json:"service_id,omitempty" db:"service_id"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty" db:"category_id"`
 	Price       int        `json:"price" db:"price" validate:"required,min=1"`
 	UserID      uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
-	StartDate   time.Time  `json:"start_date" db:"start_date" validate:"required"`
-	EndDate     *time.Time `json:"end_date,omitempty" db:"end_date"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	SplitRule   string     `json:"split_rule" db:"split_rule"`
+	// BillingInterval — периодичность списания price: monthly, quarterly или yearly.
+	BillingInterval string `json:"billing_interval" db:"billing_interval"`
+	// PaymentMethodID — способ оплаты владельца, с которого списывается подписка.
+	PaymentMethodID *uuid.UUID `json:"payment_method_id,omitempty" db:"payment_method_id"`
+	// Unused — пользователь отметил, что не пользуется подпиской.
+	Unused bool `json:"unused" db:"unused"`
+	// Status вычисляется на текущий месяц из State, фаз, пауз и end_date.
+	Status string `json:"status" db:"status"`
+	// State — хранимое состояние: active, cancel_scheduled или cancelled.
+	State           string     `json:"-" db:"state"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" db:"status_changed_at"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	StartDate       time.Time  `json:"start_date" db:"start_date" validate:"required"`
+	EndDate         *time.Time `json:"end_date,omitempty" db:"end_date"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+
+	Tags pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+
+	// Использование: время последнего использования, число использований в
+	// текущем месяце и месячная обычная цена в расчете на одно из них. Idle —
+	// использование отслеживается, но подпиской не пользовались 30 дней.
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	UsesThisMonth int        `json:"uses_this_month" db:"uses_this_month"`
+	CostPerUse    *float64   `json:"cost_per_use,omitempty" db:"cost_per_use"`
+	Idle          bool       `json:"idle" db:"idle"`
+
+	// Warnings — предупреждения при создании, например о возможном дубликате.
+	Warnings []string `json:"warnings,omitempty" db:"-"`
 }
 
+// Статусы жизненного цикла подписки. Active, CancelScheduled и Cancelled
+// также используются как хранимые состояния.
+const (
+	StatusActive          = "active"
+	StatusTrialing        = "trialing"
+	StatusPaused          = "paused"
+	StatusCancelScheduled = "cancel_scheduled"
+	StatusCancelled       = "cancelled"
+	StatusExpired         = "expired"
+)
+
+// Интервалы оплаты подписки.
+const (
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+)
+
 type CreateSubscriptionRequest struct {
-	ServiceName string  `json:"service_name" validate:"required"`
-	Price       int     `json:"price" validate:"required,min=1"`
-	UserID      string  `json:"user_id" validate:"required,uuid4"`
-	StartDate   string  `json:"start_date" validate:"required,datetime=01-2006"`
-	EndDate     *string `json:"end_date,omitempty" validate:"omitempty,datetime=01-2006"`
+	ServiceName string   `json:"service_name" validate:"required"`
+	Price       int      `json:"price" validate:"required,min=1"`
+	UserID      string   `json:"user_id" validate:"required,uuid4"`
+	StartDate   string   `json:"start_date" validate:"required,datetime=01-2006"`
+	EndDate     *string  `json:"end_date,omitempty" validate:"omitempty,datetime=01-2006"`
+	CategoryID  *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
+	Tags        []string `json:"tags,omitempty"`
+	// PaymentMethodID должен принадлежать владельцу подписки.
+	PaymentMethodID *string `json:"payment_method_id,omitempty" validate:"omitempty,uuid4"`
+	// BillingInterval по умолчанию monthly.
+	BillingInterval *string `json:"billing_interval,omitempty" validate:"omitempty,oneof=monthly quarterly yearly"`
+	// AllowDuplicate создает подписку, даже если у пользователя уже есть
+	// пересекающаяся подписка на этот сервис.
+	AllowDuplicate bool `json:"allow_duplicate,omitempty"`
 }
 
 type UpdateSubscriptionRequest struct {
 	ServiceName *string `json:"service_name,omitempty"`
 	Price       *int    `json:"price,omitempty" validate:"omitempty,min=1"`
 	EndDate     *string `json:"end_date,omitempty" validate:"omitempty,datetime=01-2006"`
+	// CategoryID: пустая строка снимает категорию.
+	CategoryID *string `json:"category_id,omitempty"`
+	// PaymentMethodID: пустая строка отвязывает способ оплаты.
+	PaymentMethodID *string `json:"payment_method_id,omitempty"`
+	BillingInterval *string `json:"billing_interval,omitempty" validate:"omitempty,oneof=monthly quarterly yearly"`
+	Unused          *bool   `json:"unused,omitempty"`
+
+	// ServiceID заполняется сервисом при разрешении service_name по каталогу.
+	ServiceID         *uuid.UUID `json:"-"`
+	CategoryUUID      *uuid.UUID `json:"-"`
+	PaymentMethodUUID *uuid.UUID `json:"-"`
+}
+
+type ListSubscriptionsRequest struct {
+	Page       int
+	Limit      int
+	UserID     *string
+	CategoryID *string
+	Tag        *string
+	Status     *string
+	// PaymentMethodID находит все подписки, списываемые с одного способа оплаты.
+	PaymentMethodID *string
+}
+
+// SubscriptionFilter — параметры выборки подписок для репозитория.
+// Фильтр по категории включает все её подкатегории.
+type SubscriptionFilter struct {
+	Limit           int
+	Offset          int
+	UserID          *uuid.UUID
+	CategoryID      *uuid.UUID
+	Tag             *string
+	Status          *string
+	PaymentMethodID *uuid.UUID
 }
 
 type SubscriptionSummary struct {
 	TotalCost int `json:"total_cost"`
+	// TotalUses — использования подписок сводки за ее период; в сводке по
+	// пользователю — его доля использований общих подписок. CostPerUse —
+	// стоимость, деленная на них.
+	TotalUses  float64        `json:"total_uses"`
+	CostPerUse *float64       `json:"cost_per_use,omitempty"`
+	Groups     []SummaryGroup `json:"groups,omitempty"`
+}
+
+// SummaryGroup — стоимость в разрезе категории верхнего уровня или тега.
+// ID пустой для подписок без категории или без тегов.
+type SummaryGroup struct {
+	ID        *uuid.UUID `json:"id,omitempty" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	TotalCost int        `json:"total_cost" db:"total_cost"`
 }
 
+const (
+	SummaryGroupByCategory = "category"
+	SummaryGroupByTag      = "tag"
+)
+
 type SummaryRequest struct {
 	StartDate   string  `json:"start_date" validate:"required,datetime=01-2006"`
 	EndDate     string  `json:"end_date" validate:"required,datetime=01-2006"`
 	UserID      *string `json:"user_id,omitempty" validate:"omitempty,uuid4"`
 	ServiceName *string `json:"service_name,omitempty"`
+	GroupBy     *string `json:"group_by,omitempty" validate:"omitempty,oneof=category tag"`
+
+	ServiceID *uuid.UUID `json:"-"`
+	// CategoryID ограничивает сводку категорией и ее подкатегориями.
+	CategoryID *uuid.UUID `json:"-"`
+}
+
+type ServiceStats struct {
+	ServiceName         string `db:"service_name"`
+	ActiveSubscriptions int    `db:"active_subscriptions"`
+	MonthlySpend        int    `db:"monthly_spend"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category — категория подписок. Категории образуют дерево через ParentID.
+type Category struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Slug      string     `json:"slug" db:"slug"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	Slug     *string `json:"slug,omitempty" validate:"omitempty,max=100"`
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid4"`
+}
+
+type UpdateCategoryRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Slug *string `json:"slug,omitempty" validate:"omitempty,max=100"`
+	// ParentID: пустая строка делает категорию корневой.
+	ParentID *string `json:"parent_id,omitempty"`
+
+	ParentUUID *uuid.UUID `json:"-"`
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type SetSubscriptionTagsRequest struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineSubscription — подписка, в которой участвует пользователь.
+type TimelineSubscription struct {
+	ID              uuid.UUID  `db:"id"`
+	ServiceID       *uuid.UUID `db:"service_id"`
+	ServiceName     string     `db:"service_name"`
+	Price           int        `db:"price"`
+	BillingInterval string     `db:"billing_interval"`
+	StartDate       time.Time  `db:"start_date"`
+	EndDate         *time.Time `db:"end_date"`
+}
+
+// TimelineCharge — доля пользователя в списании подписки за месяц.
+type TimelineCharge struct {
+	SubscriptionID uuid.UUID `db:"subscription_id"`
+	Month          time.Time `db:"month"`
+	Amount         float64   `db:"amount"`
+}
+
+// TimelineInterval — период действия одной подписки. Пустой end_date — бессрочно.
+type TimelineInterval struct {
+	SubscriptionID  uuid.UUID  `json:"subscription_id"`
+	StartDate       time.Time  `json:"start_date"`
+	EndDate         *time.Time `json:"end_date,omitempty"`
+	Price           int        `json:"price"`
+	BillingInterval string     `json:"billing_interval"`
+}
+
+// TimelineOverlap — месяцы, в которые у пользователя одновременно действовало
+// несколько подписок на сервис. Пустой end_date — пересечение продолжается.
+type TimelineOverlap struct {
+	StartDate       time.Time   `json:"start_date"`
+	EndDate         *time.Time  `json:"end_date,omitempty"`
+	Months          int         `json:"months"`
+	SubscriptionIDs []uuid.UUID `json:"subscription_ids"`
+	// DoubleCharged — сколько списано сверх самой дорогой из подписок за эти месяцы.
+	DoubleCharged int `json:"double_charged"`
+}
+
+// TimelineGap — месяцы без действующей подписки между двумя периодами.
+type TimelineGap struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Months    int       `json:"months"`
+}
+
+type TimelineService struct {
+	ServiceID     *uuid.UUID         `json:"service_id,omitempty"`
+	ServiceName   string             `json:"service_name"`
+	Intervals     []TimelineInterval `json:"intervals"`
+	Overlaps      []TimelineOverlap  `json:"overlaps"`
+	Gaps          []TimelineGap      `json:"gaps"`
+	DoubleCharged int                `json:"double_charged"`
+}
+
+type Timeline struct {
+	UserID        uuid.UUID         `json:"user_id"`
+	AsOf          time.Time         `json:"as_of"`
+	Services      []TimelineService `json:"services"`
+	DoubleCharged int               `json:"double_charged"`
+}
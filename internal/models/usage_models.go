@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecordUsageRequest struct {
+	// UsedAt — время использования в RFC 3339, по умолчанию текущее.
+	UsedAt *string `json:"used_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// Count — число использований в событии, по умолчанию 1.
+	Count int `json:"count,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// UsageMonth — использования подписки за месяц. CostPerUse — месячная
+// обычная цена, деленная на число использований.
+type UsageMonth struct {
+	Month      time.Time `json:"month" db:"month"`
+	Uses       int       `json:"uses" db:"uses"`
+	CostPerUse *float64  `json:"cost_per_use,omitempty" db:"cost_per_use"`
+}
+
+type SubscriptionUsage struct {
+	SubscriptionID uuid.UUID    `json:"subscription_id" db:"subscription_id"`
+	LastUsedAt     *time.Time   `json:"last_used_at,omitempty" db:"last_used_at"`
+	TotalUses      int          `json:"total_uses" db:"total_uses"`
+	Months         []UsageMonth `json:"months" db:"-"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+type User struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Email       *string        `json:"email,omitempty" db:"email"`
+	Timezone    string         `json:"timezone" db:"timezone"`
+	Preferences types.JSONText `json:"preferences" db:"preferences" swaggertype:"object"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time     `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+type CreateUserRequest struct {
+	Name        string          `json:"name" validate:"required,max=255"`
+	Email       *string         `json:"email,omitempty" validate:"omitempty,email"`
+	Timezone    *string         `json:"timezone,omitempty" example:"Europe/Moscow"`
+	Preferences json.RawMessage `json:"preferences,omitempty" swaggertype:"object"`
+}
+
+type UpdateUserRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	// Email: пустая строка удаляет адрес.
+	Email       *string         `json:"email,omitempty"`
+	Timezone    *string         `json:"timezone,omitempty" example:"Europe/Moscow"`
+	Preferences json.RawMessage `json:"preferences,omitempty" swaggertype:"object"`
+}
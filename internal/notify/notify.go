@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Alert — уведомление пользователю. Data содержит детали для получателя
+// (например, бюджет, порог и текущие расходы).
+type Alert struct {
+	Type      string                 `json:"type"`
+	UserID    uuid.UUID              `json:"user_id"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// LogNotifier пишет уведомления в лог сервиса.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, alert Alert) error {
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"alert_type": alert.Type,
+		"user_id":    alert.UserID.String(),
+		"data":       alert.Data,
+	}).Warn(alert.Message)
+	return nil
+}
+
+// WebhookNotifier отправляет уведомление POST-запросом с JSON-телом Alert.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Multi рассылает уведомление по всем каналам и возвращает объединенную
+// ошибку каналов, которые не смогли его доставить.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
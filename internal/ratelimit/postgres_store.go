@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresStore хранит корзины в таблице rate_limit_buckets, чтобы лимиты
+// были общими для всех инстансов сервиса. Время берется с сервера БД.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	// refill — количество токенов после пополнения с момента последнего обращения.
+	refill := `LEAST($2::DOUBLE PRECISION,
+		b.tokens + EXTRACT(EPOCH FROM (now() - b.updated_at)) * $3::DOUBLE PRECISION)`
+
+	query := `
+		INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, allowed, updated_at)
+		VALUES ($1, $2::DOUBLE PRECISION - 1, TRUE, now())
+		ON CONFLICT (bucket_key) DO UPDATE SET
+			tokens = CASE WHEN ` + refill + ` >= 1 THEN ` + refill + ` - 1 ELSE ` + refill + ` END,
+			allowed = ` + refill + ` >= 1,
+			updated_at = now()
+		RETURNING tokens, allowed`
+
+	var row struct {
+		Tokens  float64 `db:"tokens"`
+		Allowed bool    `db:"allowed"`
+	}
+	if err := s.db.GetContext(ctx, &row, query, key, float64(policy.Capacity()), policy.Rate()); err != nil {
+		return Result{}, err
+	}
+
+	return newResult(policy, row.Tokens, row.Allowed), nil
+}
+
+// DeleteIdle удаляет корзины, к которым не обращались дольше olderThan.
+func (s *PostgresStore) DeleteIdle(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < now() - $1 * INTERVAL '1 second'`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
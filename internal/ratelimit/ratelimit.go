@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy описывает token bucket: Requests запросов за Period с емкостью Burst.
+type Policy struct {
+	Name     string
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Rate возвращает скорость пополнения корзины в токенах в секунду.
+func (p Policy) Rate() float64 {
+	return float64(p.Requests) / p.Period.Seconds()
+}
+
+func (p Policy) Capacity() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Requests
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Take списывает токен из корзины key по правилам policy.
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// newResult считает заголовочные значения по остатку токенов после списания.
+func newResult(policy Policy, tokens float64, allowed bool) Result {
+	capacity := float64(policy.Capacity())
+	rate := policy.Rate()
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     policy.Capacity(),
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     time.Duration((capacity - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// Policies сопоставляет маршрутам их политики. Ключ маршрута имеет вид
+// "METHOD /path"; путь, оканчивающийся на "*", задает префикс.
+type Policies struct {
+	Default Policy
+	Routes  map[string]Policy
+}
+
+// Match выбирает политику: сначала точное совпадение, затем самый длинный префикс.
+func (p Policies) Match(method, path string) Policy {
+	if policy, ok := p.Routes[method+" "+path]; ok {
+		return policy
+	}
+
+	patterns := make([]string, 0, len(p.Routes))
+	for pattern := range p.Routes {
+		if strings.HasSuffix(pattern, "*") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+
+	for _, pattern := range patterns {
+		if strings.HasPrefix(method+" "+path, strings.TrimSuffix(pattern, "*")) {
+			return p.Routes[pattern]
+		}
+	}
+	return p.Default
+}
+
+// ParsePolicies разбирает политику по умолчанию ("120/1m:30") и список
+// маршрутов ("POST /api/summary=10/1m:5;GET /api/subscriptions/*=60/1m").
+func ParsePolicies(defaultSpec, routesSpec string) (Policies, error) {
+	defaultPolicy, err := ParsePolicy("default", defaultSpec)
+	if err != nil {
+		return Policies{}, err
+	}
+
+	policies := Policies{Default: defaultPolicy, Routes: map[string]Policy{}}
+	for _, entry := range strings.Split(routesSpec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, spec, found := strings.Cut(entry, "=")
+		if !found {
+			return Policies{}, fmt.Errorf("invalid route rate limit %q: expected ROUTE=SPEC", entry)
+		}
+
+		route = strings.Join(strings.Fields(route), " ")
+		policy, err := ParsePolicy(route, spec)
+		if err != nil {
+			return Policies{}, err
+		}
+		policies.Routes[route] = policy
+	}
+
+	return policies, nil
+}
+
+// ParsePolicy разбирает спецификацию вида "<requests>/<period>[:<burst>]".
+func ParsePolicy(name, spec string) (Policy, error) {
+	spec = strings.TrimSpace(spec)
+	rate, burstSpec, hasBurst := strings.Cut(spec, ":")
+
+	requestsSpec, periodSpec, found := strings.Cut(rate, "/")
+	if !found {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>[:<burst>]", spec)
+	}
+
+	requests, err := strconv.Atoi(requestsSpec)
+	if err != nil || requests <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", spec)
+	}
+
+	period, err := time.ParseDuration(periodSpec)
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", spec)
+	}
+
+	policy := Policy{Name: name, Requests: requests, Period: period}
+	if hasBurst {
+		policy.Burst, err = strconv.Atoi(burstSpec)
+		if err != nil || policy.Burst <= 0 {
+			return Policy{}, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", spec)
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type AnalyticsRepository interface {
+	// MonthlyMetrics возвращает показатели по месяцам [From, To] и сервисам.
+	// Пары месяц-сервис без активных, новых и отмененных подписок опускаются.
+	MonthlyMetrics(ctx context.Context, filter models.AnalyticsFilter) ([]models.ServiceMonthMetrics, error)
+}
+
+type analyticsRepo struct {
+	db *sqlx.DB
+}
+
+func NewAnalyticsRepository(db *sqlx.DB) AnalyticsRepository {
+	return &analyticsRepo{db: db}
+}
+
+func (r *analyticsRepo) MonthlyMetrics(ctx context.Context, filter models.AnalyticsFilter) ([]models.ServiceMonthMetrics, error) {
+	defer metrics.ObserveQuery("analytics", "MonthlyMetrics", time.Now())
+
+	args := []interface{}{filter.From, filter.To}
+	serviceCondition := ""
+	if filter.ServiceID != nil {
+		args = append(args, *filter.ServiceID)
+		serviceCondition = fmt.Sprintf(" AND s.service_id = $%d", len(args))
+	} else if filter.ServiceName != nil {
+		args = append(args, *filter.ServiceName)
+		serviceCondition = fmt.Sprintf(" AND s.service_name = $%d", len(args))
+	}
+
+	query := `
+		WITH months AS (
+			SELECT GENERATE_SERIES($1::DATE, $2::DATE, INTERVAL '1 month')::DATE AS month
+		),
+		subs AS (
+			SELECT s.id, s.service_name, s.price, s.billing_interval, s.start_date, s.end_date
+			FROM subscriptions s
+			WHERE s.start_date <= $2 AND (s.end_date IS NULL OR s.end_date >= $1)` + serviceCondition + `
+		),
+		active AS (
+			SELECT m.month, s.service_name,
+			       COUNT(*) AS active,
+			       SUM(COALESCE(p.price::NUMERIC, s.price::NUMERIC / billing_interval_months(s.billing_interval))) AS mrr
+			FROM months m
+			JOIN subs s ON s.start_date <= m.month AND (s.end_date IS NULL OR s.end_date >= m.month)
+			LEFT JOIN subscription_phase_periods p
+			       ON p.subscription_id = s.id AND m.month >= p.start_date AND m.month < p.end_date
+			WHERE NOT EXISTS (
+				SELECT 1 FROM subscription_pauses sp
+				WHERE sp.subscription_id = s.id
+				  AND m.month >= sp.start_date
+				  AND (sp.end_date IS NULL OR m.month < sp.end_date)
+			)
+			GROUP BY m.month, s.service_name
+		),
+		started AS (
+			SELECT s.start_date AS month, s.service_name, COUNT(*) AS new
+			FROM subs s
+			WHERE s.start_date >= $1
+			GROUP BY s.start_date, s.service_name
+		),
+		ended AS (
+			SELECT s.end_date AS month, s.service_name, COUNT(*) AS cancelled
+			FROM subs s
+			WHERE s.end_date <= $2
+			GROUP BY s.end_date, s.service_name
+		)
+		SELECT COALESCE(a.month, st.month, e.month) AS month,
+		       COALESCE(a.service_name, st.service_name, e.service_name) AS service_name,
+		       COALESCE(a.active, 0) AS active,
+		       COALESCE(ROUND(a.mrr), 0)::INTEGER AS mrr,
+		       COALESCE(st.new, 0) AS new,
+		       COALESCE(e.cancelled, 0) AS cancelled
+		FROM active a
+		FULL JOIN started st ON st.month = a.month AND st.service_name = a.service_name
+		FULL JOIN ended e
+		       ON e.month = COALESCE(a.month, st.month)
+		      AND e.service_name = COALESCE(a.service_name, st.service_name)
+		ORDER BY month, service_name`
+
+	ctx, span := startSpan(ctx, "AnalyticsRepository", "MonthlyMetrics", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "analytics",
+		"method":     "MonthlyMetrics",
+		"from":       filter.From.Format("2006-01"),
+		"to":         filter.To.Format("2006-01"),
+	}).Debug("Calculating monthly subscription metrics")
+
+	var rows []models.ServiceMonthMetrics
+	err := r.db.SelectContext(ctx, &rows, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
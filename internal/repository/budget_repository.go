@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetRepository interface {
+	Create(ctx context.Context, budget *models.Budget) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Budget, error)
+	Update(ctx context.Context, id uuid.UUID, update *models.UpdateBudgetRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает бюджеты пользователя или, без userID, все бюджеты.
+	// Бюджеты удаленных пользователей не возвращаются и не проверяются.
+	List(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error)
+	// RecordAlerts сохраняет уведомления о порогах, по которым за период еще
+	// не уведомляли, и в той же транзакции вызывает deliver с сохраненными.
+	// Если deliver вернул ошибку, записи откатываются и пороги будут
+	// отправлены при следующей проверке. Блокировка строк до конца доставки
+	// не дает другому экземпляру отправить то же уведомление.
+	RecordAlerts(ctx context.Context, alerts []models.BudgetAlert, deliver func(recorded []models.BudgetAlert) error) ([]models.BudgetAlert, error)
+}
+
+type budgetRepo struct {
+	db *sqlx.DB
+}
+
+func NewBudgetRepository(db *sqlx.DB) BudgetRepository {
+	return &budgetRepo{db: db}
+}
+
+func (r *budgetRepo) Create(ctx context.Context, budget *models.Budget) error {
+	defer metrics.ObserveQuery("budget", "Create", time.Now())
+
+	query := `
+		INSERT INTO budgets (id, user_id, scope, category_id, service_name, period, amount, currency, created_at, updated_at)
+		VALUES (:id, :user_id, :scope, :category_id, :service_name, :period, :amount, :currency, :created_at, :updated_at)`
+
+	budget.ID = uuid.New()
+	budget.CreatedAt = time.Now()
+	budget.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "Create",
+		"user_id":    budget.UserID.String(),
+		"scope":      budget.Scope,
+	}).Debug("Inserting budget")
+
+	_, err := r.db.NamedExecContext(ctx, query, budget)
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *budgetRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Budget, error) {
+	defer metrics.ObserveQuery("budget", "GetByID", time.Now())
+
+	var budget models.Budget
+	query := `SELECT * FROM budgets WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "GetByID",
+		"budget_id":  id.String(),
+	}).Debug("Selecting budget by id")
+
+	err := r.db.GetContext(ctx, &budget, query, id)
+	tracing.End(span, err)
+	return &budget, err
+}
+
+func (r *budgetRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdateBudgetRequest) error {
+	defer metrics.ObserveQuery("budget", "Update", time.Now())
+
+	query := "UPDATE budgets SET updated_at = $1"
+	args := []interface{}{time.Now()}
+
+	if update.Period != nil {
+		args = append(args, *update.Period)
+		query += fmt.Sprintf(", period = $%d", len(args))
+	}
+	if update.Amount != nil {
+		args = append(args, *update.Amount)
+		query += fmt.Sprintf(", amount = $%d", len(args))
+	}
+
+	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d", len(args))
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "Update",
+		"budget_id":  id.String(),
+		"args_count": len(args),
+	}).Debug("Updating budget")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *budgetRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("budget", "Delete", time.Now())
+
+	query := `DELETE FROM budgets WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "Delete",
+		"budget_id":  id.String(),
+	}).Debug("Deleting budget")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *budgetRepo) List(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	defer metrics.ObserveQuery("budget", "List", time.Now())
+
+	var budgets []models.Budget
+	query := `SELECT b.* FROM budgets b JOIN users u ON u.id = b.user_id WHERE u.deleted_at IS NULL`
+	args := []interface{}{}
+	if userID != nil {
+		query += ` AND b.user_id = $1`
+		args = append(args, *userID)
+	}
+	query += ` ORDER BY b.created_at`
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "List",
+		"has_user":   userID != nil,
+	}).Debug("Selecting budgets")
+
+	err := r.db.SelectContext(ctx, &budgets, query, args...)
+	tracing.End(span, err)
+	return budgets, err
+}
+
+func (r *budgetRepo) RecordAlerts(ctx context.Context, alerts []models.BudgetAlert, deliver func(recorded []models.BudgetAlert) error) ([]models.BudgetAlert, error) {
+	defer metrics.ObserveQuery("budget", "RecordAlerts", time.Now())
+
+	query := `
+		INSERT INTO budget_alerts (id, budget_id, period_start, threshold, spent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (budget_id, period_start, threshold) DO NOTHING
+		RETURNING id`
+
+	ctx, span := startSpan(ctx, "BudgetRepository", "RecordAlerts", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "budget",
+		"method":     "RecordAlerts",
+		"alerts":     len(alerts),
+	}).Debug("Recording budget alerts")
+
+	var recorded []models.BudgetAlert
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		recorded = nil
+		for _, alert := range alerts {
+			alert.ID = uuid.New()
+			alert.CreatedAt = time.Now()
+
+			var id uuid.UUID
+			err := tx.GetContext(ctx, &id, query,
+				alert.ID, alert.BudgetID, alert.PeriodStart, alert.Threshold, alert.Spent, alert.CreatedAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, alert)
+		}
+		if len(recorded) == 0 {
+			return nil
+		}
+		return deliver(recorded)
+	})
+	if err != nil {
+		recorded = nil
+	}
+	tracing.End(span, err)
+	return recorded, err
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type CatalogRepository interface {
+	Create(ctx context.Context, service *models.Service) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Service, error)
+	Update(ctx context.Context, id uuid.UUID, update *models.UpdateServiceRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]models.Service, error)
+	// Resolve ищет сервис по нормализованному имени или алиасу.
+	Resolve(ctx context.Context, normalizedName string) (*models.Service, error)
+}
+
+type catalogRepo struct {
+	db *sqlx.DB
+}
+
+func NewCatalogRepository(db *sqlx.DB) CatalogRepository {
+	return &catalogRepo{db: db}
+}
+
+func (r *catalogRepo) Create(ctx context.Context, service *models.Service) error {
+	defer metrics.ObserveQuery("catalog", "Create", time.Now())
+
+	query := `
+		INSERT INTO services (
+			id, name, aliases, category, website,
+			default_price, yearly_price, logo_url, created_at, updated_at
+		)
+		VALUES (
+			:id, :name, :aliases, :category, :website,
+			:default_price, :yearly_price, :logo_url, :created_at, :updated_at
+		)`
+
+	service.ID = uuid.New()
+	service.CreatedAt = time.Now()
+	service.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "Create",
+		"service_id": service.ID.String(),
+	}).Debug("Inserting catalog service")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, service); err != nil {
+			return mapConstraintError(err)
+		}
+		return linkSubscriptions(ctx, tx, service.ID)
+	})
+	tracing.End(span, err)
+	return err
+}
+
+func (r *catalogRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Service, error) {
+	defer metrics.ObserveQuery("catalog", "GetByID", time.Now())
+
+	var service models.Service
+	query := `SELECT * FROM services WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "GetByID",
+		"service_id": id.String(),
+	}).Debug("Selecting catalog service by id")
+
+	err := r.db.GetContext(ctx, &service, query, id)
+	tracing.End(span, err)
+	return &service, err
+}
+
+// Update меняет запись каталога. При переименовании каноническое имя
+// переносится и в привязанные подписки.
+func (r *catalogRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdateServiceRequest) error {
+	defer metrics.ObserveQuery("catalog", "Update", time.Now())
+
+	query := "UPDATE services SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if update.Name != nil {
+		query += fmt.Sprintf(", name = $%d", argIndex)
+		args = append(args, *update.Name)
+		argIndex++
+	}
+
+	if update.Aliases != nil {
+		query += fmt.Sprintf(", aliases = $%d", argIndex)
+		args = append(args, pq.Array(*update.Aliases))
+		argIndex++
+	}
+
+	if update.Category != nil {
+		query += fmt.Sprintf(", category = $%d", argIndex)
+		args = append(args, *update.Category)
+		argIndex++
+	}
+
+	if update.Website != nil {
+		query += fmt.Sprintf(", website = $%d", argIndex)
+		args = append(args, *update.Website)
+		argIndex++
+	}
+
+	if update.DefaultPrice != nil {
+		query += fmt.Sprintf(", default_price = $%d", argIndex)
+		args = append(args, *update.DefaultPrice)
+		argIndex++
+	}
+
+	if update.YearlyPrice != nil {
+		query += fmt.Sprintf(", yearly_price = $%d", argIndex)
+		args = append(args, *update.YearlyPrice)
+		argIndex++
+	}
+
+	if update.LogoURL != nil {
+		query += fmt.Sprintf(", logo_url = $%d", argIndex)
+		args = append(args, *update.LogoURL)
+		argIndex++
+	}
+
+	query += " WHERE id = $" + fmt.Sprint(argIndex)
+	args = append(args, id)
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "Update",
+		"service_id": id.String(),
+		"args_count": len(args),
+	}).Debug("Updating catalog service")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return mapConstraintError(err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		if update.Name != nil {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE subscriptions SET service_name = $1, updated_at = $2 WHERE service_id = $3`,
+				*update.Name, time.Now(), id,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if update.Name != nil || update.Aliases != nil {
+			return linkSubscriptions(ctx, tx, id)
+		}
+		return nil
+	})
+	tracing.End(span, err)
+	return err
+}
+
+func (r *catalogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("catalog", "Delete", time.Now())
+
+	query := `DELETE FROM services WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "Delete",
+		"service_id": id.String(),
+	}).Debug("Deleting catalog service")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *catalogRepo) List(ctx context.Context, limit, offset int) ([]models.Service, error) {
+	defer metrics.ObserveQuery("catalog", "List", time.Now())
+
+	var services []models.Service
+	query := `SELECT * FROM services ORDER BY name LIMIT $1 OFFSET $2`
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "List",
+		"limit":      limit,
+		"offset":     offset,
+	}).Debug("Selecting catalog services")
+
+	err := r.db.SelectContext(ctx, &services, query, limit, offset)
+	tracing.End(span, err)
+	return services, err
+}
+
+func (r *catalogRepo) Resolve(ctx context.Context, normalizedName string) (*models.Service, error) {
+	defer metrics.ObserveQuery("catalog", "Resolve", time.Now())
+
+	var service models.Service
+	query := `
+		SELECT * FROM services
+		WHERE LOWER(name) = $1 OR aliases @> ARRAY[$1]::TEXT[]
+		ORDER BY (LOWER(name) = $1) DESC
+		LIMIT 1`
+
+	ctx, span := startSpan(ctx, "CatalogRepository", "Resolve", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "catalog",
+		"method":     "Resolve",
+		"name":       normalizedName,
+	}).Debug("Resolving catalog service by name")
+
+	err := r.db.GetContext(ctx, &service, query, normalizedName)
+	tracing.End(span, err)
+	return &service, err
+}
+
+// linkSubscriptions привязывает к сервису подписки без service_id, название
+// которых совпадает с одним из его алиасов.
+func linkSubscriptions(ctx context.Context, tx *sqlx.Tx, serviceID uuid.UUID) error {
+	query := `
+		UPDATE subscriptions s
+		SET service_id = sv.id, service_name = sv.name, updated_at = $2
+		FROM services sv
+		WHERE sv.id = $1
+		  AND s.service_id IS NULL
+		  AND LOWER(REGEXP_REPLACE(TRIM(s.service_name), '\s+', ' ', 'g')) = ANY(sv.aliases)`
+
+	result, err := tx.ExecContext(ctx, query, serviceID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	linked, _ := result.RowsAffected()
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"service_id": serviceID.String(),
+		"linked":     linked,
+	}).Debug("Linked subscriptions to catalog service")
+	return nil
+}
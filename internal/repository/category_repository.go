@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	Update(ctx context.Context, id uuid.UUID, update *models.UpdateCategoryRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]models.Category, error)
+	// FindByName ищет категорию по имени или slug без учета регистра.
+	FindByName(ctx context.Context, name string) (*models.Category, error)
+	// IsInSubtree сообщает, находится ли candidate в поддереве root (включая сам root).
+	IsInSubtree(ctx context.Context, root, candidate uuid.UUID) (bool, error)
+}
+
+type categoryRepo struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) CategoryRepository {
+	return &categoryRepo{db: db}
+}
+
+func (r *categoryRepo) Create(ctx context.Context, category *models.Category) error {
+	defer metrics.ObserveQuery("category", "Create", time.Now())
+
+	query := `
+		INSERT INTO categories (id, name, slug, parent_id, created_at, updated_at)
+		VALUES (:id, :name, :slug, :parent_id, :created_at, :updated_at)`
+
+	category.ID = uuid.New()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":  "category",
+		"method":      "Create",
+		"category_id": category.ID.String(),
+	}).Debug("Inserting category")
+
+	_, err := r.db.NamedExecContext(ctx, query, category)
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	defer metrics.ObserveQuery("category", "GetByID", time.Now())
+
+	var category models.Category
+	query := `SELECT * FROM categories WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":  "category",
+		"method":      "GetByID",
+		"category_id": id.String(),
+	}).Debug("Selecting category by id")
+
+	err := r.db.GetContext(ctx, &category, query, id)
+	tracing.End(span, err)
+	return &category, err
+}
+
+func (r *categoryRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdateCategoryRequest) error {
+	defer metrics.ObserveQuery("category", "Update", time.Now())
+
+	query := "UPDATE categories SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if update.Name != nil {
+		query += fmt.Sprintf(", name = $%d", argIndex)
+		args = append(args, *update.Name)
+		argIndex++
+	}
+
+	if update.Slug != nil {
+		query += fmt.Sprintf(", slug = $%d", argIndex)
+		args = append(args, *update.Slug)
+		argIndex++
+	}
+
+	if update.ParentID != nil {
+		query += fmt.Sprintf(", parent_id = $%d", argIndex)
+		args = append(args, update.ParentUUID)
+		argIndex++
+	}
+
+	query += " WHERE id = $" + fmt.Sprint(argIndex)
+	args = append(args, id)
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":  "category",
+		"method":      "Update",
+		"category_id": id.String(),
+		"args_count":  len(args),
+	}).Debug("Updating category")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *categoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("category", "Delete", time.Now())
+
+	query := `DELETE FROM categories WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":  "category",
+		"method":      "Delete",
+		"category_id": id.String(),
+	}).Debug("Deleting category")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *categoryRepo) List(ctx context.Context) ([]models.Category, error) {
+	defer metrics.ObserveQuery("category", "List", time.Now())
+
+	var categories []models.Category
+	query := `SELECT * FROM categories ORDER BY parent_id NULLS FIRST, name`
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "category",
+		"method":     "List",
+	}).Debug("Selecting categories")
+
+	err := r.db.SelectContext(ctx, &categories, query)
+	tracing.End(span, err)
+	return categories, err
+}
+
+func (r *categoryRepo) FindByName(ctx context.Context, name string) (*models.Category, error) {
+	defer metrics.ObserveQuery("category", "FindByName", time.Now())
+
+	var category models.Category
+	query := `
+		SELECT * FROM categories
+		WHERE LOWER(name) = LOWER($1) OR slug = LOWER($1)
+		ORDER BY (slug = LOWER($1)) DESC
+		LIMIT 1`
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "FindByName", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "category",
+		"method":     "FindByName",
+		"name":       name,
+	}).Debug("Selecting category by name")
+
+	err := r.db.GetContext(ctx, &category, query, name)
+	tracing.End(span, err)
+	return &category, err
+}
+
+func (r *categoryRepo) IsInSubtree(ctx context.Context, root, candidate uuid.UUID) (bool, error) {
+	defer metrics.ObserveQuery("category", "IsInSubtree", time.Now())
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c JOIN subtree t ON c.parent_id = t.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`
+
+	ctx, span := startSpan(ctx, "CategoryRepository", "IsInSubtree", query)
+
+	var inSubtree bool
+	err := r.db.GetContext(ctx, &inSubtree, query, root, candidate)
+	tracing.End(span, err)
+	return inSubtree, err
+}
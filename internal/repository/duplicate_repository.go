@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type DuplicateRepository interface {
+	// FindOverlapping возвращает подписки пользователя на тот же сервис,
+	// период которых пересекается с периодом sub.
+	FindOverlapping(ctx context.Context, sub *models.Subscription) ([]models.Subscription, error)
+	List(ctx context.Context, userID *uuid.UUID) ([]models.DuplicatePair, error)
+	// Merge переносит в keepID период, платежи, переходы, историю цен, использование, теги,
+	// участников, паузы вне периода keepID и способ оплаты mergeID, сохраняет снимок mergeID
+	// и удаляет ее. Начало keepID с фазами не сдвигается, чтобы не сместить фазы.
+	// Возвращает sql.ErrNoRows, если одной из подписок нет.
+	Merge(ctx context.Context, keepID, mergeID uuid.UUID) (*models.SubscriptionMerge, error)
+	ListMerges(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionMerge, error)
+}
+
+type duplicateRepo struct {
+	db *sqlx.DB
+}
+
+func NewDuplicateRepository(db *sqlx.DB) DuplicateRepository {
+	return &duplicateRepo{db: db}
+}
+
+// sameService считает подписки a и b подписками на один сервис: по каталогу,
+// а если у одной из них сервис не определен — по имени без учета регистра.
+const sameService = `(a.service_id = b.service_id
+	OR ((a.service_id IS NULL OR b.service_id IS NULL) AND LOWER(a.service_name) = LOWER(b.service_name)))`
+
+// periodsOverlap — месячные периоды a и b пересекаются; пустой end_date — бессрочно.
+const periodsOverlap = `a.start_date <= COALESCE(b.end_date, 'infinity'::DATE)
+	AND b.start_date <= COALESCE(a.end_date, 'infinity'::DATE)`
+
+func (r *duplicateRepo) FindOverlapping(ctx context.Context, sub *models.Subscription) ([]models.Subscription, error) {
+	defer metrics.ObserveQuery("duplicate", "FindOverlapping", time.Now())
+
+	query := `
+		WITH b AS (
+			SELECT $1::UUID AS user_id, $2::UUID AS service_id, $3::VARCHAR AS service_name,
+			       $4::DATE AS start_date, $5::DATE AS end_date
+		)` + subscriptionSelect + `
+		JOIN subscriptions a ON a.id = s.id
+		CROSS JOIN b
+		WHERE a.user_id = b.user_id AND ` + sameService + ` AND ` + periodsOverlap + `
+		ORDER BY s.start_date`
+
+	ctx, span := startSpan(ctx, "DuplicateRepository", "FindOverlapping", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "duplicate",
+		"method":     "FindOverlapping",
+		"user_id":    sub.UserID.String(),
+	}).Debug("Selecting overlapping subscriptions")
+
+	var subscriptions []models.Subscription
+	err := r.db.SelectContext(ctx, &subscriptions, query,
+		sub.UserID, sub.ServiceID, sub.ServiceName, sub.StartDate, sub.EndDate)
+	tracing.End(span, err)
+	return subscriptions, err
+}
+
+func (r *duplicateRepo) List(ctx context.Context, userID *uuid.UUID) ([]models.DuplicatePair, error) {
+	defer metrics.ObserveQuery("duplicate", "List", time.Now())
+
+	var args []interface{}
+	userCondition := ""
+	if userID != nil {
+		args = append(args, *userID)
+		userCondition = fmt.Sprintf(" AND a.user_id = $%d", len(args))
+	}
+
+	query := `
+		SELECT a.user_id,
+		       a.service_name,
+		       a.id AS subscription_id,
+		       b.id AS duplicate_id,
+		       GREATEST(a.start_date, b.start_date) AS overlap_start,
+		       LEAST(a.end_date, b.end_date) AS overlap_end
+		FROM subscriptions a
+		JOIN subscriptions b
+		  ON b.user_id = a.user_id
+		 AND (b.start_date, b.id) > (a.start_date, a.id)
+		WHERE ` + sameService + ` AND ` + periodsOverlap + userCondition + `
+		ORDER BY a.user_id, LOWER(a.service_name), a.start_date, b.start_date`
+
+	ctx, span := startSpan(ctx, "DuplicateRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":  "duplicate",
+		"method":      "List",
+		"has_user_id": userID != nil,
+	}).Debug("Selecting duplicate subscriptions")
+
+	var pairs []models.DuplicatePair
+	err := r.db.SelectContext(ctx, &pairs, query, args...)
+	tracing.End(span, err)
+	return pairs, err
+}
+
+func (r *duplicateRepo) Merge(ctx context.Context, keepID, mergeID uuid.UUID) (*models.SubscriptionMerge, error) {
+	defer metrics.ObserveQuery("duplicate", "Merge", time.Now())
+
+	query := `
+		INSERT INTO subscription_merges (id, subscription_id, merged_subscription_id, merged_snapshot, created_at)
+		SELECT $1, $2, s.id,
+		       JSONB_BUILD_OBJECT(
+		           'subscription', TO_JSONB(s),
+		           'tags', ARRAY(
+		               SELECT t.name FROM subscription_tags st
+		               JOIN tags t ON t.id = st.tag_id
+		               WHERE st.subscription_id = s.id
+		           ),
+		           'members', (SELECT COALESCE(JSONB_AGG(TO_JSONB(m)), '[]') FROM subscription_members m WHERE m.subscription_id = s.id),
+		           'phases', (SELECT COALESCE(JSONB_AGG(TO_JSONB(p) ORDER BY p.sequence), '[]') FROM subscription_phases p WHERE p.subscription_id = s.id),
+		           'pauses', (SELECT COALESCE(JSONB_AGG(TO_JSONB(p) ORDER BY p.start_date), '[]') FROM subscription_pauses p WHERE p.subscription_id = s.id)
+		       ),
+		       $4
+		FROM subscriptions s
+		WHERE s.id = $3
+		RETURNING *`
+
+	merge := &models.SubscriptionMerge{}
+
+	ctx, span := startSpan(ctx, "DuplicateRepository", "Merge", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "duplicate",
+		"method":     "Merge",
+		"keep_id":    keepID.String(),
+		"merge_id":   mergeID.String(),
+	}).Debug("Merging duplicate subscriptions")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var locked []struct {
+			ID    uuid.UUID `db:"id"`
+			State string    `db:"state"`
+		}
+		if err := tx.SelectContext(ctx, &locked,
+			`SELECT id, state FROM subscriptions WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`, keepID, mergeID); err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return sql.ErrNoRows
+		}
+		var keepState string
+		for _, row := range locked {
+			if row.ID == keepID {
+				keepState = row.State
+			}
+		}
+
+		if err := tx.GetContext(ctx, merge, query, uuid.New(), keepID, mergeID, time.Now()); err != nil {
+			return err
+		}
+
+		// Паузы поглощенной подписки переносятся на месяцы, которые добавляются
+		// к периоду оставленной: внутри ее периода действуют ее собственные паузы.
+		// Периоды считаются до расширения.
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_pauses (subscription_id, start_date, end_date, created_at, updated_at)
+			SELECT k.id, p.start_date, LEAST(COALESCE(p.end_date, k.start_date), k.start_date), p.created_at, $3
+			FROM subscription_pauses p
+			JOIN subscriptions k ON k.id = $1
+			WHERE p.subscription_id = $2 AND p.start_date < k.start_date
+			  AND NOT EXISTS (SELECT 1 FROM subscription_phases ph WHERE ph.subscription_id = k.id)
+			UNION ALL
+			SELECT k.id, GREATEST(p.start_date, (k.end_date + INTERVAL '1 month')::DATE), p.end_date, p.created_at, $3
+			FROM subscription_pauses p
+			JOIN subscriptions k ON k.id = $1
+			WHERE p.subscription_id = $2 AND k.end_date IS NOT NULL
+			  AND (p.end_date IS NULL OR p.end_date > k.end_date + INTERVAL '1 month')`,
+			keepID, mergeID, time.Now()); err != nil {
+			return err
+		}
+
+		// Бессрочная поглощенная подписка продолжает оставленную, поэтому
+		// отмена оставленной снимается и записывается переходом. Фазы
+		// отсчитываются от start_date, поэтому начало подписки с фазами не
+		// переносится.
+		now := time.Now()
+		var state string
+		if err := tx.GetContext(ctx, &state, `
+			UPDATE subscriptions k
+			SET start_date = CASE WHEN EXISTS (SELECT 1 FROM subscription_phases p WHERE p.subscription_id = k.id)
+			                      THEN k.start_date ELSE LEAST(k.start_date, m.start_date) END,
+			    end_date = CASE WHEN k.end_date IS NULL OR m.end_date IS NULL THEN NULL
+			                    ELSE GREATEST(k.end_date, m.end_date) END,
+			    state = CASE WHEN k.end_date IS NULL OR m.end_date IS NULL THEN 'active' ELSE k.state END,
+			    status_changed_at = CASE WHEN (k.end_date IS NULL OR m.end_date IS NULL) AND k.state <> 'active'
+			                             THEN $3 ELSE k.status_changed_at END,
+			    payment_method_id = COALESCE(k.payment_method_id, m.payment_method_id),
+			    updated_at = $3
+			FROM subscriptions m
+			WHERE k.id = $1 AND m.id = $2
+			RETURNING k.state`, keepID, mergeID, now); err != nil {
+			return err
+		}
+		if state != keepState {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO subscription_transitions (id, subscription_id, from_status, to_status, created_at)
+				VALUES ($1, $2, $3, $4, $5)`, uuid.New(), keepID, keepState, state, now); err != nil {
+				return err
+			}
+		}
+
+		for _, table := range []string{"payments", "subscription_transitions", "subscription_merges", "subscription_price_changes"} {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE `+table+` SET subscription_id = $1 WHERE subscription_id = $2`, keepID, mergeID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_usage (subscription_id, last_used_at, total_uses)
+			SELECT $1, last_used_at, total_uses FROM subscription_usage WHERE subscription_id = $2
+			ON CONFLICT (subscription_id) DO UPDATE
+			SET last_used_at = GREATEST(subscription_usage.last_used_at, EXCLUDED.last_used_at),
+			    total_uses = subscription_usage.total_uses + EXCLUDED.total_uses`, keepID, mergeID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_usage_monthly (subscription_id, month, uses)
+			SELECT $1, month, uses FROM subscription_usage_monthly WHERE subscription_id = $2
+			ON CONFLICT (subscription_id, month) DO UPDATE
+			SET uses = subscription_usage_monthly.uses + EXCLUDED.uses`, keepID, mergeID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_tags (subscription_id, tag_id)
+			SELECT $1, tag_id FROM subscription_tags WHERE subscription_id = $2
+			ON CONFLICT DO NOTHING`, keepID, mergeID); err != nil {
+			return err
+		}
+
+		// Доли участников переносятся только при одинаковом правиле разделения.
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_members (subscription_id, user_id, share_percent, share_amount, created_at)
+			SELECT k.id, m.user_id, m.share_percent, m.share_amount, m.created_at
+			FROM subscription_members m
+			JOIN subscriptions d ON d.id = m.subscription_id
+			JOIN subscriptions k ON k.id = $1
+			WHERE m.subscription_id = $2 AND k.split_rule = d.split_rule AND m.user_id <> k.user_id
+			ON CONFLICT DO NOTHING`, keepID, mergeID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, mergeID)
+		return err
+	})
+	tracing.End(span, err)
+	return merge, err
+}
+
+func (r *duplicateRepo) ListMerges(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionMerge, error) {
+	defer metrics.ObserveQuery("duplicate", "ListMerges", time.Now())
+
+	var merges []models.SubscriptionMerge
+	query := `SELECT * FROM subscription_merges WHERE subscription_id = $1 ORDER BY created_at`
+
+	ctx, span := startSpan(ctx, "DuplicateRepository", "ListMerges", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "duplicate",
+		"method":          "ListMerges",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Selecting subscription merges")
+
+	err := r.db.SelectContext(ctx, &merges, query, subscriptionID)
+	tracing.End(span, err)
+	return merges, err
+}
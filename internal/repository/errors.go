@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrAlreadyExists возвращается при нарушении ограничения уникальности.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalidReference возвращается, когда внешний ключ ссылается на несуществующую запись.
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	// ErrStaleState возвращается, когда запись изменилась после чтения.
+	ErrStaleState = errors.New("record was modified concurrently")
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+func mapConstraintError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case uniqueViolationCode:
+		return ErrAlreadyExists
+	case foreignKeyViolationCode:
+		return ErrInvalidReference
+	default:
+		return err
+	}
+}
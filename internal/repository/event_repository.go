@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type EventRepository interface {
+	// Upcoming возвращает события подписок в интервале [from, to].
+	Upcoming(ctx context.Context, from, to time.Time, userID *uuid.UUID) ([]models.UpcomingEvent, error)
+}
+
+type eventRepo struct {
+	db *sqlx.DB
+}
+
+func NewEventRepository(db *sqlx.DB) EventRepository {
+	return &eventRepo{db: db}
+}
+
+func (r *eventRepo) Upcoming(ctx context.Context, from, to time.Time, userID *uuid.UUID) ([]models.UpcomingEvent, error) {
+	defer metrics.ObserveQuery("event", "Upcoming", time.Now())
+
+	args := []interface{}{from, to}
+	userCondition := ""
+	if userID != nil {
+		args = append(args, *userID)
+		userCondition = fmt.Sprintf(" AND s.user_id = $%d", len(args))
+	}
+
+	// Окончание фазы — первый месяц следующей фазы; если фаз больше нет,
+	// начинает действовать обычная цена подписки. Истечение способа оплаты
+	// датируется ближайшим платным списанием, которое по нему не пройдет.
+	query := `
+		SELECT * FROM (
+			SELECT p.phase_type || '_end' AS type,
+			       p.end_date AS date,
+			       s.id AS subscription_id,
+			       s.user_id,
+			       s.service_name,
+			       COALESCE(next.price, s.price) AS price
+			FROM subscription_phase_periods p
+			JOIN subscriptions s ON s.id = p.subscription_id
+			LEFT JOIN subscription_phases next
+			       ON next.subscription_id = p.subscription_id AND next.sequence = p.sequence + 1
+			WHERE p.end_date >= $1 AND p.end_date <= $2
+			  AND (s.end_date IS NULL OR s.end_date >= p.end_date)` + userCondition + `
+			UNION ALL
+			SELECT '` + models.EventPaymentMethodExpiry + `',
+			       nb.next_billing_date,
+			       s.id,
+			       s.user_id,
+			       s.service_name,
+			       nb.amount
+			FROM (` + nextBillingSelect + `) nb
+			JOIN subscriptions s ON s.id = nb.subscription_id
+			JOIN payment_methods pm ON pm.id = s.payment_method_id
+			WHERE pm.expiry_month < nb.next_billing_date` + userCondition + `
+		) events
+		ORDER BY date, service_name`
+
+	ctx, span := startSpan(ctx, "EventRepository", "Upcoming", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "event",
+		"method":     "Upcoming",
+		"from":       from.Format("2006-01-02"),
+		"to":         to.Format("2006-01-02"),
+	}).Debug("Selecting upcoming events")
+
+	var events []models.UpcomingEvent
+	err := r.db.SelectContext(ctx, &events, query, args...)
+	tracing.End(span, err)
+	return events, err
+}
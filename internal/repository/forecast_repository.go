@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type ForecastRepository interface {
+	// MonthlyCosts возвращает стоимость по месяцам и сервисам за период
+	// запроса по тем же правилам, что и сводка: цены фаз, паузы, интервалы
+	// оплаты, даты окончания и доли в общих подписках.
+	MonthlyCosts(ctx context.Context, req models.SummaryRequest) ([]models.ForecastRow, error)
+}
+
+type forecastRepo struct {
+	db *sqlx.DB
+}
+
+func NewForecastRepository(db *sqlx.DB) ForecastRepository {
+	return &forecastRepo{db: db}
+}
+
+func (r *forecastRepo) MonthlyCosts(ctx context.Context, req models.SummaryRequest) ([]models.ForecastRow, error) {
+	defer metrics.ObserveQuery("forecast", "MonthlyCosts", time.Now())
+
+	from, cost := summarySource(req)
+	where, args := summaryConditions(req)
+	query := `
+		SELECT c.charge_month AS month,
+		       s.service_name,
+		       ROUND(SUM(` + cost + `))::INTEGER AS cost
+		FROM ` + from + `
+		WHERE ` + where + `
+		GROUP BY c.charge_month, s.service_name
+		ORDER BY c.charge_month, cost DESC, s.service_name`
+
+	ctx, span := startSpan(ctx, "ForecastRepository", "MonthlyCosts", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "forecast",
+		"method":     "MonthlyCosts",
+		"args_count": len(args),
+	}).Debug("Calculating monthly cost forecast")
+
+	var rows []models.ForecastRow
+	err := r.db.SelectContext(ctx, &rows, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
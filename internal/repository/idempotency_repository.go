@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type IdempotencyRepository interface {
+	// Reserve атомарно занимает ключ. Если ключ уже занят и не истек,
+	// возвращает существующую запись и reserved == false.
+	Reserve(ctx context.Context, key, requestHash string, expiresAt time.Time) (record *models.IdempotencyRecord, reserved bool, err error)
+	Complete(ctx context.Context, key string, statusCode int, body []byte, contentType string) error
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) IdempotencyRepository {
+	return &idempotencyRepo{db: db}
+}
+
+func (r *idempotencyRepo) Reserve(ctx context.Context, key, requestHash string, expiresAt time.Time) (*models.IdempotencyRecord, bool, error) {
+	defer metrics.ObserveQuery("idempotency", "Reserve", time.Now())
+
+	query := `
+		INSERT INTO idempotency_keys (idempotency_key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			response_body = NULL,
+			content_type = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < $3
+		RETURNING *`
+
+	ctx, span := startSpan(ctx, "IdempotencyRepository", "Reserve", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "idempotency",
+		"method":          "Reserve",
+		"idempotency_key": key,
+	}).Debug("Reserving idempotency key")
+
+	var record models.IdempotencyRecord
+	err := r.db.GetContext(ctx, &record, query, key, requestHash, time.Now(), expiresAt)
+	if err == nil {
+		tracing.End(span, nil)
+		return &record, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		tracing.End(span, err)
+		return nil, false, err
+	}
+
+	err = r.db.GetContext(ctx, &record, `SELECT * FROM idempotency_keys WHERE idempotency_key = $1`, key)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, false, err
+	}
+	return &record, false, nil
+}
+
+func (r *idempotencyRepo) Complete(ctx context.Context, key string, statusCode int, body []byte, contentType string) error {
+	defer metrics.ObserveQuery("idempotency", "Complete", time.Now())
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2, content_type = $3
+		WHERE idempotency_key = $4`
+
+	ctx, span := startSpan(ctx, "IdempotencyRepository", "Complete", query)
+
+	_, err := r.db.ExecContext(ctx, query, statusCode, body, contentType, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *idempotencyRepo) Release(ctx context.Context, key string) error {
+	defer metrics.ObserveQuery("idempotency", "Release", time.Now())
+
+	query := `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND status_code IS NULL`
+
+	ctx, span := startSpan(ctx, "IdempotencyRepository", "Release", query)
+
+	_, err := r.db.ExecContext(ctx, query, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *idempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("idempotency", "DeleteExpired", time.Now())
+
+	query := `DELETE FROM idempotency_keys WHERE expires_at < $1`
+
+	ctx, span := startSpan(ctx, "IdempotencyRepository", "DeleteExpired", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now())
+	tracing.End(span, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
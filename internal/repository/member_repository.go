@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type MemberRepository interface {
+	// ListShares возвращает доли владельца и участников подписки.
+	ListShares(ctx context.Context, subscriptionID uuid.UUID) ([]models.MemberShare, error)
+	// Replace задает правило разделения и полностью заменяет список участников.
+	Replace(ctx context.Context, subscriptionID uuid.UUID, splitRule string, members []models.SubscriptionMember) error
+	Remove(ctx context.Context, subscriptionID, userID uuid.UUID) error
+}
+
+type memberRepo struct {
+	db *sqlx.DB
+}
+
+func NewMemberRepository(db *sqlx.DB) MemberRepository {
+	return &memberRepo{db: db}
+}
+
+func (r *memberRepo) ListShares(ctx context.Context, subscriptionID uuid.UUID) ([]models.MemberShare, error) {
+	defer metrics.ObserveQuery("member", "ListShares", time.Now())
+
+	var shares []models.MemberShare
+	query := `
+		SELECT sh.user_id, sh.role, m.share_percent, m.share_amount,
+		       sh.share_ratio, ROUND(s.price * sh.share_ratio, 2) AS monthly_cost
+		FROM subscription_shares sh
+		JOIN subscriptions s ON s.id = sh.subscription_id
+		LEFT JOIN subscription_members m
+		       ON m.subscription_id = sh.subscription_id AND m.user_id = sh.user_id AND sh.role = 'member'
+		WHERE sh.subscription_id = $1
+		ORDER BY sh.role DESC, sh.share_ratio DESC`
+
+	ctx, span := startSpan(ctx, "MemberRepository", "ListShares", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "member",
+		"method":          "ListShares",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Selecting subscription shares")
+
+	err := r.db.SelectContext(ctx, &shares, query, subscriptionID)
+	tracing.End(span, err)
+	return shares, err
+}
+
+func (r *memberRepo) Replace(ctx context.Context, subscriptionID uuid.UUID, splitRule string, members []models.SubscriptionMember) error {
+	defer metrics.ObserveQuery("member", "Replace", time.Now())
+
+	query := `
+		INSERT INTO subscription_members (subscription_id, user_id, share_percent, share_amount)
+		VALUES (:subscription_id, :user_id, :share_percent, :share_amount)`
+
+	ctx, span := startSpan(ctx, "MemberRepository", "Replace", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "member",
+		"method":          "Replace",
+		"subscription_id": subscriptionID.String(),
+		"split_rule":      splitRule,
+		"members":         len(members),
+	}).Debug("Replacing subscription members")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE subscriptions SET split_rule = $1, updated_at = $2 WHERE id = $3`,
+			splitRule, time.Now(), subscriptionID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_members WHERE subscription_id = $1`, subscriptionID); err != nil {
+			return err
+		}
+
+		for i := range members {
+			members[i].SubscriptionID = subscriptionID
+			if _, err := tx.NamedExecContext(ctx, query, members[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *memberRepo) Remove(ctx context.Context, subscriptionID, userID uuid.UUID) error {
+	defer metrics.ObserveQuery("member", "Remove", time.Now())
+
+	query := `DELETE FROM subscription_members WHERE subscription_id = $1 AND user_id = $2`
+
+	ctx, span := startSpan(ctx, "MemberRepository", "Remove", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "member",
+		"method":          "Remove",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Removing subscription member")
+
+	result, err := r.db.ExecContext(ctx, query, subscriptionID, userID)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
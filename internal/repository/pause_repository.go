@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PauseRepository interface {
+	// Create добавляет паузу. Возвращает ErrAlreadyExists, если она
+	// пересекается с другой паузой подписки. Если пауза уже началась, смена
+	// статуса записывается переходом.
+	Create(ctx context.Context, pause *models.SubscriptionPause) error
+	// Resume завершает паузу, действующую в месяце resumeMonth или позже.
+	// Запланированная пауза, которая еще не началась, удаляется. Возвращает
+	// sql.ErrNoRows, если такой паузы нет. Смена статуса записывается переходом.
+	Resume(ctx context.Context, subscriptionID uuid.UUID, resumeMonth time.Time) error
+	List(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionPause, error)
+}
+
+type pauseRepo struct {
+	db *sqlx.DB
+}
+
+func NewPauseRepository(db *sqlx.DB) PauseRepository {
+	return &pauseRepo{db: db}
+}
+
+func (r *pauseRepo) Create(ctx context.Context, pause *models.SubscriptionPause) error {
+	defer metrics.ObserveQuery("pause", "Create", time.Now())
+
+	query := `
+		INSERT INTO subscription_pauses (id, subscription_id, start_date, end_date, created_at, updated_at)
+		VALUES (:id, :subscription_id, :start_date, :end_date, :created_at, :updated_at)`
+
+	pause.ID = uuid.New()
+	pause.CreatedAt = time.Now()
+	pause.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "PauseRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "pause",
+		"method":          "Create",
+		"subscription_id": pause.SubscriptionID.String(),
+	}).Debug("Inserting subscription pause")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		status, err := lockStatus(ctx, tx, pause.SubscriptionID)
+		if err != nil {
+			return err
+		}
+
+		var overlaps bool
+		err = tx.GetContext(ctx, &overlaps, `
+			SELECT EXISTS (
+				SELECT 1 FROM subscription_pauses
+				WHERE subscription_id = $1
+				  AND (end_date IS NULL OR end_date > $2)
+				  AND ($3::DATE IS NULL OR start_date < $3)
+			)`, pause.SubscriptionID, pause.StartDate, pause.EndDate)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return ErrAlreadyExists
+		}
+
+		if _, err := tx.NamedExecContext(ctx, query, pause); err != nil {
+			return err
+		}
+		return recordStatusChange(ctx, tx, pause.SubscriptionID, status)
+	})
+	tracing.End(span, err)
+	return err
+}
+
+func (r *pauseRepo) Resume(ctx context.Context, subscriptionID uuid.UUID, resumeMonth time.Time) error {
+	defer metrics.ObserveQuery("pause", "Resume", time.Now())
+
+	query := `
+		SELECT * FROM subscription_pauses
+		WHERE subscription_id = $1 AND (end_date IS NULL OR end_date > $2)
+		ORDER BY start_date
+		LIMIT 1
+		FOR UPDATE`
+
+	ctx, span := startSpan(ctx, "PauseRepository", "Resume", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "pause",
+		"method":          "Resume",
+		"subscription_id": subscriptionID.String(),
+		"resume_month":    resumeMonth.Format("2006-01"),
+	}).Debug("Resuming subscription")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		status, err := lockStatus(ctx, tx, subscriptionID)
+		if err != nil {
+			return err
+		}
+
+		var pause models.SubscriptionPause
+		if err := tx.GetContext(ctx, &pause, query, subscriptionID, resumeMonth); err != nil {
+			return err
+		}
+
+		if !pause.StartDate.Before(resumeMonth) {
+			_, err = tx.ExecContext(ctx, `DELETE FROM subscription_pauses WHERE id = $1`, pause.ID)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE subscription_pauses SET end_date = $1, updated_at = $2 WHERE id = $3`,
+				resumeMonth, time.Now(), pause.ID)
+		}
+		if err != nil {
+			return err
+		}
+		return recordStatusChange(ctx, tx, subscriptionID, status)
+	})
+	tracing.End(span, err)
+	return err
+}
+
+func (r *pauseRepo) List(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionPause, error) {
+	defer metrics.ObserveQuery("pause", "List", time.Now())
+
+	var pauses []models.SubscriptionPause
+	query := `SELECT * FROM subscription_pauses WHERE subscription_id = $1 ORDER BY start_date`
+
+	ctx, span := startSpan(ctx, "PauseRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "pause",
+		"method":          "List",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Selecting subscription pauses")
+
+	err := r.db.SelectContext(ctx, &pauses, query, subscriptionID)
+	tracing.End(span, err)
+	return pauses, err
+}
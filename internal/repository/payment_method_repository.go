@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// nextBillingSelect выбирает для каждой подписки ближайший платный месяц
+// после $1 в пределах [DATE_TRUNC('month', $1), $2]. Бесплатные месяцы
+// пробного периода и паузы не считаются списаниями.
+const nextBillingSelect = `
+	SELECT DISTINCT ON (c.subscription_id)
+	       c.subscription_id, c.charge_month AS next_billing_date, c.amount
+	FROM subscription_monthly_charges(DATE_TRUNC('month', $1::DATE)::DATE, $2::DATE) c
+	WHERE c.amount > 0 AND c.charge_month > $1::DATE
+	ORDER BY c.subscription_id, c.charge_month`
+
+type PaymentMethodRepository interface {
+	Create(ctx context.Context, method *models.PaymentMethod) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentMethod, error)
+	Update(ctx context.Context, id uuid.UUID, update *models.UpdatePaymentMethodRequest) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PaymentMethod, error)
+	// Expiring возвращает подписки, чей способ оплаты истекает раньше
+	// ближайшего платного списания после after (но не позже until).
+	Expiring(ctx context.Context, after, until time.Time, userID *uuid.UUID) ([]models.ExpiringPaymentMethod, error)
+}
+
+type paymentMethodRepo struct {
+	db *sqlx.DB
+}
+
+func NewPaymentMethodRepository(db *sqlx.DB) PaymentMethodRepository {
+	return &paymentMethodRepo{db: db}
+}
+
+func (r *paymentMethodRepo) Create(ctx context.Context, method *models.PaymentMethod) error {
+	defer metrics.ObserveQuery("payment_method", "Create", time.Now())
+
+	query := `
+		INSERT INTO payment_methods (id, user_id, type, label, expiry_month, created_at, updated_at)
+		VALUES (:id, :user_id, :type, :label, :expiry_month, :created_at, :updated_at)`
+
+	method.ID = uuid.New()
+	method.CreatedAt = time.Now()
+	method.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment_method",
+		"method":     "Create",
+		"user_id":    method.UserID.String(),
+		"type":       method.Type,
+	}).Debug("Inserting payment method")
+
+	_, err := r.db.NamedExecContext(ctx, query, method)
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *paymentMethodRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentMethod, error) {
+	defer metrics.ObserveQuery("payment_method", "GetByID", time.Now())
+
+	var method models.PaymentMethod
+	query := `SELECT * FROM payment_methods WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":        "payment_method",
+		"method":            "GetByID",
+		"payment_method_id": id.String(),
+	}).Debug("Selecting payment method by id")
+
+	err := r.db.GetContext(ctx, &method, query, id)
+	tracing.End(span, err)
+	return &method, err
+}
+
+func (r *paymentMethodRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdatePaymentMethodRequest) error {
+	defer metrics.ObserveQuery("payment_method", "Update", time.Now())
+
+	query := "UPDATE payment_methods SET updated_at = $1"
+	args := []interface{}{time.Now()}
+
+	if update.Label != nil {
+		args = append(args, *update.Label)
+		query += fmt.Sprintf(", label = $%d", len(args))
+	}
+	if update.ExpiryMonth != nil {
+		args = append(args, update.ExpiryDate)
+		query += fmt.Sprintf(", expiry_month = $%d", len(args))
+	}
+
+	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d", len(args))
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":        "payment_method",
+		"method":            "Update",
+		"payment_method_id": id.String(),
+		"args_count":        len(args),
+	}).Debug("Updating payment method")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *paymentMethodRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("payment_method", "Delete", time.Now())
+
+	query := `DELETE FROM payment_methods WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":        "payment_method",
+		"method":            "Delete",
+		"payment_method_id": id.String(),
+	}).Debug("Deleting payment method")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *paymentMethodRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PaymentMethod, error) {
+	defer metrics.ObserveQuery("payment_method", "ListByUser", time.Now())
+
+	var methods []models.PaymentMethod
+	query := `SELECT * FROM payment_methods WHERE user_id = $1 ORDER BY created_at`
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "ListByUser", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment_method",
+		"method":     "ListByUser",
+		"user_id":    userID.String(),
+	}).Debug("Selecting user payment methods")
+
+	err := r.db.SelectContext(ctx, &methods, query, userID)
+	tracing.End(span, err)
+	return methods, err
+}
+
+func (r *paymentMethodRepo) Expiring(ctx context.Context, after, until time.Time, userID *uuid.UUID) ([]models.ExpiringPaymentMethod, error) {
+	defer metrics.ObserveQuery("payment_method", "Expiring", time.Now())
+
+	args := []interface{}{after, until}
+	userCondition := ""
+	if userID != nil {
+		args = append(args, *userID)
+		userCondition = fmt.Sprintf(" AND s.user_id = $%d", len(args))
+	}
+
+	// Карта действует до конца expiry_month, поэтому истекает раньше
+	// списания, если ее последний месяц меньше месяца списания.
+	query := `
+		SELECT s.id AS subscription_id, s.service_name, s.user_id,
+		       pm.id AS payment_method_id, pm.label, pm.expiry_month,
+		       nb.next_billing_date, nb.amount
+		FROM (` + nextBillingSelect + `) nb
+		JOIN subscriptions s ON s.id = nb.subscription_id
+		JOIN payment_methods pm ON pm.id = s.payment_method_id
+		WHERE pm.expiry_month < nb.next_billing_date` + userCondition + `
+		ORDER BY nb.next_billing_date, s.service_name`
+
+	ctx, span := startSpan(ctx, "PaymentMethodRepository", "Expiring", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment_method",
+		"method":     "Expiring",
+		"after":      after.Format("2006-01-02"),
+		"until":      until.Format("2006-01-02"),
+	}).Debug("Selecting subscriptions with expiring payment methods")
+
+	var expiring []models.ExpiringPaymentMethod
+	err := r.db.SelectContext(ctx, &expiring, query, args...)
+	tracing.End(span, err)
+	return expiring, err
+}
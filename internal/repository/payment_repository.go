@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error)
+	// Reconcile сопоставляет ожидаемые помесячные списания с платежами за период.
+	Reconcile(ctx context.Context, filter models.ReconciliationFilter) ([]models.ReconciliationItem, error)
+}
+
+type paymentRepo struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) PaymentRepository {
+	return &paymentRepo{db: db}
+}
+
+func (r *paymentRepo) Create(ctx context.Context, payment *models.Payment) error {
+	defer metrics.ObserveQuery("payment", "Create", time.Now())
+
+	query := `
+		INSERT INTO payments (id, subscription_id, amount, currency, paid_at, payment_method, reference, created_at)
+		VALUES (:id, :subscription_id, :amount, :currency, :paid_at, :payment_method, :reference, :created_at)`
+
+	payment.ID = uuid.New()
+	payment.CreatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "PaymentRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "payment",
+		"method":          "Create",
+		"subscription_id": payment.SubscriptionID.String(),
+	}).Debug("Inserting payment")
+
+	_, err := r.db.NamedExecContext(ctx, query, payment)
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *paymentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	defer metrics.ObserveQuery("payment", "GetByID", time.Now())
+
+	var payment models.Payment
+	query := `SELECT * FROM payments WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "PaymentRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment",
+		"method":     "GetByID",
+		"payment_id": id.String(),
+	}).Debug("Selecting payment by id")
+
+	err := r.db.GetContext(ctx, &payment, query, id)
+	tracing.End(span, err)
+	return &payment, err
+}
+
+func (r *paymentRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("payment", "Delete", time.Now())
+
+	query := `DELETE FROM payments WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "PaymentRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment",
+		"method":     "Delete",
+		"payment_id": id.String(),
+	}).Debug("Deleting payment")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *paymentRepo) List(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error) {
+	defer metrics.ObserveQuery("payment", "List", time.Now())
+
+	var payments []models.Payment
+	query := `SELECT p.* FROM payments p JOIN subscriptions s ON s.id = p.subscription_id`
+	args := []interface{}{}
+	conditions := []string{}
+
+	if filter.SubscriptionID != nil {
+		args = append(args, *filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("p.subscription_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("p.paid_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("p.paid_at <= $%d", len(args)))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY p.paid_at DESC, p.created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	ctx, span := startSpan(ctx, "PaymentRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment",
+		"method":     "List",
+		"limit":      filter.Limit,
+		"offset":     filter.Offset,
+		"conditions": len(conditions),
+	}).Debug("Selecting payments")
+
+	err := r.db.SelectContext(ctx, &payments, query, args...)
+	tracing.End(span, err)
+	return payments, err
+}
+
+func (r *paymentRepo) Reconcile(ctx context.Context, filter models.ReconciliationFilter) ([]models.ReconciliationItem, error) {
+	defer metrics.ObserveQuery("payment", "Reconcile", time.Now())
+
+	var items []models.ReconciliationItem
+	args := []interface{}{filter.From, filter.To, models.DefaultCurrency}
+	conditions := []string{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", len(args)))
+	}
+	if filter.SubscriptionID != nil {
+		args = append(args, *filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("s.id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		WITH expected AS (
+			SELECT c.subscription_id, c.charge_month AS month, c.amount
+			FROM subscription_monthly_charges($1, $2) c
+		),
+		paid AS (
+			SELECT p.subscription_id, DATE_TRUNC('month', p.paid_at)::DATE AS month,
+			       COALESCE(SUM(p.amount) FILTER (WHERE p.currency = $3), 0)::INTEGER AS amount,
+			       COUNT(*) AS payments,
+			       COUNT(*) FILTER (WHERE p.currency <> $3) AS foreign_payments
+			FROM payments p
+			WHERE p.paid_at >= $1 AND p.paid_at < ($2::DATE + INTERVAL '1 month')
+			GROUP BY 1, 2
+		)
+		SELECT s.id AS subscription_id, s.service_name,
+		       COALESCE(e.month, pd.month) AS month,
+		       COALESCE(e.amount, 0) AS expected_amount,
+		       COALESCE(pd.amount, 0) AS paid_amount,
+		       COALESCE(pd.payments, 0) AS payments,
+		       COALESCE(pd.foreign_payments, 0) AS foreign_payments
+		FROM expected e
+		FULL JOIN paid pd ON pd.subscription_id = e.subscription_id AND pd.month = e.month
+		JOIN subscriptions s ON s.id = COALESCE(e.subscription_id, pd.subscription_id)` + where + `
+		ORDER BY month, s.service_name, s.id`
+
+	ctx, span := startSpan(ctx, "PaymentRepository", "Reconcile", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "payment",
+		"method":     "Reconcile",
+		"from":       filter.From.Format("2006-01"),
+		"to":         filter.To.Format("2006-01"),
+		"conditions": len(conditions),
+	}).Debug("Reconciling payments")
+
+	err := r.db.SelectContext(ctx, &items, query, args...)
+	tracing.End(span, err)
+	return items, err
+}
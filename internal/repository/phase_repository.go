@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PhaseRepository interface {
+	// List возвращает начальные фазы подписки с календарными границами.
+	List(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionPhase, error)
+	// Replace полностью заменяет начальные фазы подписки.
+	Replace(ctx context.Context, subscriptionID uuid.UUID, phases []models.SubscriptionPhase) error
+}
+
+type phaseRepo struct {
+	db *sqlx.DB
+}
+
+func NewPhaseRepository(db *sqlx.DB) PhaseRepository {
+	return &phaseRepo{db: db}
+}
+
+func (r *phaseRepo) List(ctx context.Context, subscriptionID uuid.UUID) ([]models.SubscriptionPhase, error) {
+	defer metrics.ObserveQuery("phase", "List", time.Now())
+
+	var phases []models.SubscriptionPhase
+	query := `
+		SELECT id, subscription_id, sequence, phase_type, duration_months, price, start_date, end_date
+		FROM subscription_phase_periods
+		WHERE subscription_id = $1
+		ORDER BY sequence`
+
+	ctx, span := startSpan(ctx, "PhaseRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "phase",
+		"method":          "List",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Selecting subscription phases")
+
+	err := r.db.SelectContext(ctx, &phases, query, subscriptionID)
+	tracing.End(span, err)
+	return phases, err
+}
+
+func (r *phaseRepo) Replace(ctx context.Context, subscriptionID uuid.UUID, phases []models.SubscriptionPhase) error {
+	defer metrics.ObserveQuery("phase", "Replace", time.Now())
+
+	query := `
+		INSERT INTO subscription_phases (id, subscription_id, sequence, phase_type, duration_months, price)
+		VALUES (:id, :subscription_id, :sequence, :phase_type, :duration_months, :price)`
+
+	ctx, span := startSpan(ctx, "PhaseRepository", "Replace", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "phase",
+		"method":          "Replace",
+		"subscription_id": subscriptionID.String(),
+		"phases":          len(phases),
+	}).Debug("Replacing subscription phases")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT true FROM subscriptions WHERE id = $1 FOR UPDATE`, subscriptionID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_phases WHERE subscription_id = $1`, subscriptionID); err != nil {
+			return err
+		}
+
+		for i := range phases {
+			phases[i].ID = uuid.New()
+			phases[i].SubscriptionID = subscriptionID
+			phases[i].Sequence = i + 1
+			if _, err := tx.NamedExecContext(ctx, query, phases[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	tracing.End(span, err)
+	return err
+}
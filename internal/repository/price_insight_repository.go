@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PriceInsightRepository interface {
+	// Refresh заново считает распределения цен по сервисам и аномалии на
+	// месяц month и возвращает число найденных аномалий.
+	Refresh(ctx context.Context, month time.Time, rules models.PriceInsightRules) (int, error)
+	Stats(ctx context.Context, serviceName *string) ([]models.ServicePriceStats, error)
+	Anomalies(ctx context.Context, filter models.PriceAnomalyFilter) ([]models.PriceAnomaly, error)
+}
+
+type priceInsightRepo struct {
+	db *sqlx.DB
+}
+
+func NewPriceInsightRepository(db *sqlx.DB) PriceInsightRepository {
+	return &priceInsightRepo{db: db}
+}
+
+// priceInsightLockKey сериализует пересчет аномалий между экземплярами сервиса.
+const priceInsightLockKey = 4_403_271_551
+
+// activeMonthlyPrices — подписки, действующие в месяце $1, с обычной ценой,
+// приведенной к месяцу по интервалу оплаты.
+const activeMonthlyPrices = `
+	SELECT s.id, s.user_id, s.service_name,
+	       s.price::NUMERIC / billing_interval_months(s.billing_interval) AS monthly_price
+	FROM subscriptions s
+	WHERE s.start_date <= $1 AND (s.end_date IS NULL OR s.end_date >= $1)`
+
+// priceIncreases — изменения цены начиная с $1, при которых цена, приведенная
+// к месяцу, выросла не меньше чем в 1 + $2 раза. Смена интервала оплаты без
+// роста месячной цены повышением не считается.
+const priceIncreases = `
+	FROM (
+		SELECT pc.subscription_id, pc.changed_at,
+		       pc.old_price::NUMERIC / billing_interval_months(pc.old_billing_interval) AS old_monthly,
+		       pc.new_price::NUMERIC / billing_interval_months(pc.new_billing_interval) AS new_monthly
+		FROM subscription_price_changes pc
+		WHERE pc.changed_at >= $1 AND pc.old_price > 0
+	) pc
+	JOIN subscriptions s ON s.id = pc.subscription_id
+	WHERE pc.new_monthly >= pc.old_monthly * (1 + $2::NUMERIC)`
+
+const priceAnomalyInsert = `
+	INSERT INTO price_anomalies
+		(kind, service_name, subscription_id, user_id, price, baseline, ratio, affected, observed_at, detected_at)`
+
+func (r *priceInsightRepo) Refresh(ctx context.Context, month time.Time, rules models.PriceInsightRules) (int, error) {
+	defer metrics.ObserveQuery("price_insight", "Refresh", time.Now())
+
+	statsQuery := `
+		INSERT INTO service_price_stats
+			(service_name, subscriptions, min_price, p25_price, median_price, p75_price, max_price, computed_at)
+		SELECT MIN(a.service_name), COUNT(*), MIN(a.monthly_price),
+		       PERCENTILE_CONT(0.25) WITHIN GROUP (ORDER BY a.monthly_price),
+		       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY a.monthly_price),
+		       PERCENTILE_CONT(0.75) WITHIN GROUP (ORDER BY a.monthly_price),
+		       MAX(a.monthly_price), $2
+		FROM (` + activeMonthlyPrices + `) a
+		GROUP BY LOWER(a.service_name)`
+
+	// Выброс — цена выше верхней границы Тьюки (Q3 + 1.5 * IQR) и заметно
+	// выше медианы, чтобы не отмечать копеечные отличия при нулевом разбросе.
+	outliersQuery := priceAnomalyInsert + `
+		SELECT 'outlier', st.service_name, a.id, a.user_id, ROUND(a.monthly_price, 2), st.median_price,
+		       ROUND(a.monthly_price / st.median_price, 2), 1, $2, $2
+		FROM (` + activeMonthlyPrices + `) a
+		JOIN service_price_stats st ON LOWER(st.service_name) = LOWER(a.service_name)
+		WHERE st.subscriptions >= $3 AND st.median_price > 0
+		  AND a.monthly_price > st.p75_price + 1.5 * (st.p75_price - st.p25_price)
+		  AND a.monthly_price >= st.median_price * (1 + $4::NUMERIC)`
+
+	increasesQuery := priceAnomalyInsert + `
+		SELECT 'increase', s.service_name, s.id, s.user_id, ROUND(pc.new_monthly, 2), ROUND(pc.old_monthly, 2),
+		       ROUND(pc.new_monthly / pc.old_monthly, 2), 1, pc.changed_at, $3` + priceIncreases
+
+	serviceIncreasesQuery := priceAnomalyInsert + `
+		SELECT 'service_increase', MIN(s.service_name), NULL, NULL,
+		       ROUND(AVG(pc.new_monthly), 2), ROUND(AVG(pc.old_monthly), 2),
+		       ROUND(AVG(pc.new_monthly) / AVG(pc.old_monthly), 2),
+		       COUNT(DISTINCT s.user_id), MAX(pc.changed_at), $3` + priceIncreases + `
+		GROUP BY LOWER(s.service_name)
+		HAVING COUNT(DISTINCT s.user_id) >= $4`
+
+	ctx, span := startSpan(ctx, "PriceInsightRepository", "Refresh", statsQuery)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "price_insight",
+		"method":     "Refresh",
+		"month":      month.Format("2006-01"),
+		"since":      rules.Since.Format(time.RFC3339),
+	}).Debug("Refreshing price insights")
+
+	detectedAt := time.Now()
+	var anomalies int
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, priceInsightLockKey); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM service_price_stats`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM price_anomalies`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, statsQuery, month, detectedAt); err != nil {
+			return err
+		}
+
+		steps := []struct {
+			query string
+			args  []interface{}
+		}{
+			{outliersQuery, []interface{}{month, detectedAt, rules.MinSample, rules.IncreaseThreshold}},
+			{increasesQuery, []interface{}{rules.Since, rules.IncreaseThreshold, detectedAt}},
+			{serviceIncreasesQuery, []interface{}{rules.Since, rules.IncreaseThreshold, detectedAt, rules.MinIncreaseUsers}},
+		}
+		for _, step := range steps {
+			result, err := tx.ExecContext(ctx, step.query, step.args...)
+			if err != nil {
+				return err
+			}
+			inserted, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			anomalies += int(inserted)
+		}
+		return nil
+	})
+	tracing.End(span, err)
+	return anomalies, err
+}
+
+func (r *priceInsightRepo) Stats(ctx context.Context, serviceName *string) ([]models.ServicePriceStats, error) {
+	defer metrics.ObserveQuery("price_insight", "Stats", time.Now())
+
+	query := `SELECT * FROM service_price_stats`
+	var args []interface{}
+	if serviceName != nil {
+		query += ` WHERE LOWER(service_name) = LOWER($1)`
+		args = append(args, *serviceName)
+	}
+	query += ` ORDER BY subscriptions DESC, service_name`
+
+	ctx, span := startSpan(ctx, "PriceInsightRepository", "Stats", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "price_insight",
+		"method":     "Stats",
+	}).Debug("Selecting service price stats")
+
+	var stats []models.ServicePriceStats
+	err := r.db.SelectContext(ctx, &stats, query, args...)
+	tracing.End(span, err)
+	return stats, err
+}
+
+func (r *priceInsightRepo) Anomalies(ctx context.Context, filter models.PriceAnomalyFilter) ([]models.PriceAnomaly, error) {
+	defer metrics.ObserveQuery("price_insight", "Anomalies", time.Now())
+
+	var conditions []string
+	var args []interface{}
+	if filter.ServiceName != nil {
+		args = append(args, *filter.ServiceName)
+		conditions = append(conditions, fmt.Sprintf("LOWER(a.service_name) = LOWER($%d)", len(args)))
+	}
+	if filter.Kind != nil {
+		args = append(args, *filter.Kind)
+		conditions = append(conditions, fmt.Sprintf("a.kind = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf(`(a.user_id = $%[1]d OR (a.user_id IS NULL AND EXISTS (
+			SELECT 1 FROM subscriptions s
+			JOIN subscription_shares sh ON sh.subscription_id = s.id
+			WHERE sh.user_id = $%[1]d AND LOWER(s.service_name) = LOWER(a.service_name))))`, len(args)))
+	}
+
+	query := `SELECT a.* FROM price_anomalies a`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY a.ratio DESC, a.observed_at DESC`
+
+	ctx, span := startSpan(ctx, "PriceInsightRepository", "Anomalies", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "price_insight",
+		"method":     "Anomalies",
+		"args_count": len(args),
+	}).Debug("Selecting price anomalies")
+
+	var anomalies []models.PriceAnomaly
+	err := r.db.SelectContext(ctx, &anomalies, query, args...)
+	tracing.End(span, err)
+	return anomalies, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type RecommendationRepository interface {
+	// Candidates возвращает подписки пользователя, которые действуют в месяце
+	// month, включая последний месяц перед end_date, не запланированы к отмене
+	// и не стоят на паузе. Рекомендации строятся только по подпискам, которыми
+	// пользователь владеет: отменить чужую он не может.
+	Candidates(ctx context.Context, userID uuid.UUID, month time.Time) ([]models.RecommendationCandidate, error)
+}
+
+type recommendationRepo struct {
+	db *sqlx.DB
+}
+
+func NewRecommendationRepository(db *sqlx.DB) RecommendationRepository {
+	return &recommendationRepo{db: db}
+}
+
+func (r *recommendationRepo) Candidates(ctx context.Context, userID uuid.UUID, month time.Time) ([]models.RecommendationCandidate, error) {
+	defer metrics.ObserveQuery("recommendation", "Candidates", time.Now())
+
+	query := `
+		SELECT s.id, s.service_name, s.price,
+		       billing_interval_months(s.billing_interval) AS interval_months,
+		       sh.share_ratio, s.unused,
+		       COALESCE(su.last_used_at < CURRENT_DATE - 30, false) AS idle,
+		       COALESCE(s.category_id::TEXT, 'catalog:' || LOWER(sv.category)) AS category_key,
+		       COALESCE(c.name, sv.category) AS category_name,
+		       sv.yearly_price
+		FROM subscriptions s
+		JOIN subscription_shares sh ON sh.subscription_id = s.id
+		LEFT JOIN services sv ON sv.id = s.service_id
+		LEFT JOIN categories c ON c.id = s.category_id
+		LEFT JOIN subscription_usage su ON su.subscription_id = s.id
+		WHERE sh.user_id = $1 AND sh.role = 'owner'
+		  AND s.state = 'active'
+		  AND s.start_date <= $2 AND (s.end_date IS NULL OR s.end_date >= $2)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM subscription_pauses sp
+		      WHERE sp.subscription_id = s.id
+		        AND sp.start_date <= $2 AND (sp.end_date IS NULL OR sp.end_date > $2)
+		  )
+		ORDER BY s.service_name, s.start_date`
+
+	ctx, span := startSpan(ctx, "RecommendationRepository", "Candidates", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "recommendation",
+		"method":     "Candidates",
+		"user_id":    userID.String(),
+	}).Debug("Selecting recommendation candidates")
+
+	var candidates []models.RecommendationCandidate
+	err := r.db.SelectContext(ctx, &candidates, query, userID, month)
+	tracing.End(span, err)
+	return candidates, err
+}
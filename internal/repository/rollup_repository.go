@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type RollupRepository interface {
+	// State возвращает окно агрегатов или sql.ErrNoRows, если они не построены.
+	State(ctx context.Context) (*models.RollupState, error)
+	// Rebuild заново строит агрегаты за месяцы [from, to].
+	Rebuild(ctx context.Context, from, to time.Time) error
+	// Refresh пересчитывает агрегаты пользователей, отмеченных триггерами
+	// после изменений, и возвращает их число. Возвращает sql.ErrNoRows, если
+	// агрегаты не построены.
+	Refresh(ctx context.Context) (int, error)
+	// Check сравнивает агрегаты с расчетом по подпискам в пределах окна.
+	// Пользователи, ожидающие пересчета, не проверяются.
+	Check(ctx context.Context) ([]models.RollupMismatch, error)
+}
+
+type rollupRepo struct {
+	db *sqlx.DB
+}
+
+func NewRollupRepository(db *sqlx.DB) RollupRepository {
+	return &rollupRepo{db: db}
+}
+
+// rollupLockKey сериализует построение агрегатов между экземплярами сервиса.
+const rollupLockKey = 4_403_271_550
+
+// rollupAggregate считает строки агрегатов за месяцы [$1, $2] по тем же
+// правилам, что и сводка (см. summarySource).
+const rollupAggregate = `
+	SELECT c.charge_month AS month,
+	       sh.user_id,
+	       s.service_name,
+	       s.service_id,
+	       SUM(c.amount * sh.share_ratio) AS share_cost,
+	       SUM(CASE WHEN sh.role = 'owner' THEN c.amount ELSE 0 END)::INTEGER AS owned_cost,
+	       COUNT(*)::INTEGER AS subscriptions
+	FROM subscription_monthly_charges($1, $2) c
+	JOIN subscriptions s ON s.id = c.subscription_id
+	JOIN subscription_shares sh ON sh.subscription_id = s.id
+	%s
+	GROUP BY c.charge_month, sh.user_id, s.service_name, s.service_id`
+
+const rollupInsert = `
+	INSERT INTO monthly_spend_rollups (month, user_id, service_name, service_id, share_cost, owned_cost, subscriptions)`
+
+func (r *rollupRepo) State(ctx context.Context) (*models.RollupState, error) {
+	defer metrics.ObserveQuery("rollup", "State", time.Now())
+
+	var state models.RollupState
+	query := `SELECT covered_from, covered_to, rebuilt_at, refreshed_at FROM spend_rollup_state`
+
+	ctx, span := startSpan(ctx, "RollupRepository", "State", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "rollup",
+		"method":     "State",
+	}).Debug("Selecting rollup state")
+
+	err := r.db.GetContext(ctx, &state, query)
+	tracing.End(span, err)
+	return &state, err
+}
+
+func (r *rollupRepo) Rebuild(ctx context.Context, from, to time.Time) error {
+	defer metrics.ObserveQuery("rollup", "Rebuild", time.Now())
+
+	query := rollupInsert + fmt.Sprintf(rollupAggregate, "")
+
+	ctx, span := startSpan(ctx, "RollupRepository", "Rebuild", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "rollup",
+		"method":     "Rebuild",
+		"from":       from.Format("2006-01"),
+		"to":         to.Format("2006-01"),
+	}).Debug("Rebuilding spend rollups")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, rollupLockKey); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM spend_rollup_dirty`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM monthly_spend_rollups`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, from, to); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO spend_rollup_state (id, covered_from, covered_to, rebuilt_at, refreshed_at)
+			VALUES (true, $1, $2, $3, $3)
+			ON CONFLICT (id) DO UPDATE
+			SET covered_from = EXCLUDED.covered_from,
+			    covered_to = EXCLUDED.covered_to,
+			    rebuilt_at = EXCLUDED.rebuilt_at,
+			    refreshed_at = EXCLUDED.refreshed_at`, from, to, time.Now())
+		return err
+	})
+	tracing.End(span, err)
+	return err
+}
+
+func (r *rollupRepo) Refresh(ctx context.Context) (int, error) {
+	defer metrics.ObserveQuery("rollup", "Refresh", time.Now())
+
+	query := rollupInsert + fmt.Sprintf(rollupAggregate, "WHERE sh.user_id = ANY($3)")
+
+	ctx, span := startSpan(ctx, "RollupRepository", "Refresh", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "rollup",
+		"method":     "Refresh",
+	}).Debug("Refreshing dirty spend rollups")
+
+	var users []uuid.UUID
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, rollupLockKey); err != nil {
+			return err
+		}
+
+		var state models.RollupState
+		if err := tx.GetContext(ctx, &state, `SELECT covered_from, covered_to, rebuilt_at, refreshed_at FROM spend_rollup_state`); err != nil {
+			return err
+		}
+
+		// Отметки удаляются первыми: изменения, которые придут во время
+		// пересчета, будут ждать блокировки и отметят пользователя снова.
+		if err := tx.SelectContext(ctx, &users, `DELETE FROM spend_rollup_dirty RETURNING user_id`); err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM monthly_spend_rollups WHERE user_id = ANY($1)`, pq.Array(users)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, state.CoveredFrom, state.CoveredTo, pq.Array(users)); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE spend_rollup_state SET refreshed_at = $1`, time.Now())
+		return err
+	})
+	tracing.End(span, err)
+	return len(users), err
+}
+
+func (r *rollupRepo) Check(ctx context.Context) ([]models.RollupMismatch, error) {
+	defer metrics.ObserveQuery("rollup", "Check", time.Now())
+
+	query := `
+		WITH raw AS (` + fmt.Sprintf(rollupAggregate, "WHERE sh.user_id NOT IN (SELECT user_id FROM spend_rollup_dirty)") + `
+		),
+		rollup AS (
+			SELECT r.*
+			FROM monthly_spend_rollups r
+			WHERE r.user_id NOT IN (SELECT user_id FROM spend_rollup_dirty)
+		)
+		SELECT COALESCE(r.month, w.month) AS month,
+		       COALESCE(r.user_id, w.user_id) AS user_id,
+		       COALESCE(r.service_name, w.service_name) AS service_name,
+		       COALESCE(r.service_id, w.service_id) AS service_id,
+		       r.share_cost AS rollup_share_cost,
+		       w.share_cost AS raw_share_cost,
+		       r.owned_cost AS rollup_owned_cost,
+		       w.owned_cost AS raw_owned_cost,
+		       r.subscriptions AS rollup_subscriptions,
+		       w.subscriptions AS raw_subscriptions
+		FROM rollup r
+		FULL JOIN raw w
+		       ON w.month = r.month
+		      AND w.user_id = r.user_id
+		      AND w.service_name = r.service_name
+		      AND w.service_id IS NOT DISTINCT FROM r.service_id
+		WHERE r.share_cost IS DISTINCT FROM w.share_cost
+		   OR r.owned_cost IS DISTINCT FROM w.owned_cost
+		   OR r.subscriptions IS DISTINCT FROM w.subscriptions
+		ORDER BY month, user_id, service_name`
+
+	ctx, span := startSpan(ctx, "RollupRepository", "Check", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "rollup",
+		"method":     "Check",
+	}).Debug("Checking spend rollups against subscriptions")
+
+	var mismatches []models.RollupMismatch
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var state models.RollupState
+		if err := tx.GetContext(ctx, &state, `SELECT covered_from, covered_to, rebuilt_at, refreshed_at FROM spend_rollup_state`); err != nil {
+			return err
+		}
+		return tx.SelectContext(ctx, &mismatches, query, state.CoveredFrom, state.CoveredTo)
+	})
+	tracing.End(span, err)
+	return mismatches, err
+}
+
+// rollupSummary считает сводку по агрегатам. Возвращает sql.ErrNoRows, если
+// агрегаты не покрывают период, устарели для запроса или фильтры запроса
+// ими не поддерживаются; тогда сводка считается по подпискам.
+func rollupSummary(ctx context.Context, db *sqlx.DB, req models.SummaryRequest) (int, error) {
+	if req.CategoryID != nil {
+		return 0, sql.ErrNoRows
+	}
+
+	startDate, _ := time.Parse("01-2006", req.StartDate)
+	endDate, _ := time.Parse("01-2006", req.EndDate)
+
+	args := []interface{}{startDate, endDate}
+	cost := "r.owned_cost"
+	dirty := "SELECT 1 FROM spend_rollup_dirty"
+	conditions := []string{"r.month >= $1", "r.month <= $2"}
+
+	if req.UserID != nil {
+		userID, _ := uuid.Parse(*req.UserID)
+		args = append(args, userID)
+		cost = "r.share_cost"
+		dirty += fmt.Sprintf(" WHERE user_id = $%d", len(args))
+		conditions = append(conditions, fmt.Sprintf("r.user_id = $%d", len(args)))
+	}
+
+	if req.ServiceID != nil {
+		args = append(args, *req.ServiceID)
+		conditions = append(conditions, fmt.Sprintf("r.service_id = $%d", len(args)))
+	} else if req.ServiceName != nil {
+		args = append(args, *req.ServiceName)
+		conditions = append(conditions, fmt.Sprintf("r.service_name = $%d", len(args)))
+	}
+
+	query := `
+		SELECT (
+			SELECT COALESCE(ROUND(SUM(` + cost + `)), 0)::INTEGER
+			FROM monthly_spend_rollups r
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		)
+		FROM spend_rollup_state st
+		WHERE st.covered_from <= $1 AND st.covered_to >= $2
+		  AND NOT EXISTS (` + dirty + `)`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "GetSummaryRollup", query)
+
+	var totalCost int
+	err := db.GetContext(ctx, &totalCost, query, args...)
+	spanErr := err
+	if errors.Is(err, sql.ErrNoRows) {
+		spanErr = nil
+	}
+	tracing.End(span, spanErr)
+	return totalCost, err
+}
@@ -2,14 +2,20 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"subscribe_project/internal/metrics"
 	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 )
 
 type SubscriptionRepository interface {
@@ -17,8 +23,15 @@ type SubscriptionRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
 	Update(ctx context.Context, id uuid.UUID, update *models.UpdateSubscriptionRequest) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]models.Subscription, error)
+	List(ctx context.Context, filter models.SubscriptionFilter) ([]models.Subscription, error)
 	GetSummary(ctx context.Context, req models.SummaryRequest) (int, error)
+	GetSummaryGroups(ctx context.Context, req models.SummaryRequest) ([]models.SummaryGroup, error)
+	GetServiceStats(ctx context.Context) ([]models.ServiceStats, error)
+	// ChangeState применяет переход жизненного цикла и записывает его в историю.
+	// Возвращает sql.ErrNoRows, если подписка не найдена, и ErrStaleState, если
+	// ее состояние изменилось после чтения.
+	ChangeState(ctx context.Context, id uuid.UUID, change *models.StateChange) error
+	ListTransitions(ctx context.Context, id uuid.UUID) ([]models.SubscriptionTransition, error)
 }
 
 type subscriptionRepo struct {
@@ -29,41 +42,123 @@ func NewSubscriptionRepository(db *sqlx.DB) SubscriptionRepository {
 	return &subscriptionRepo{db: db}
 }
 
+// subscriptionStatus вычисляет статус подписки на текущий месяц. Отмена на
+// конец периода после последнего оплаченного месяца считается отменой.
+const subscriptionStatus = `
+	CASE
+	    WHEN s.state = 'cancelled' THEN 'cancelled'
+	    WHEN s.end_date < DATE_TRUNC('month', CURRENT_DATE) THEN
+	        CASE WHEN s.state = 'cancel_scheduled' THEN 'cancelled' ELSE 'expired' END
+	    WHEN s.state = 'cancel_scheduled' THEN 'cancel_scheduled'
+	    WHEN EXISTS (
+	        SELECT 1 FROM subscription_pauses sp
+	        WHERE sp.subscription_id = s.id
+	          AND sp.start_date <= CURRENT_DATE
+	          AND (sp.end_date IS NULL OR sp.end_date > CURRENT_DATE)
+	    ) THEN 'paused'
+	    WHEN EXISTS (
+	        SELECT 1 FROM subscription_phase_periods pp
+	        WHERE pp.subscription_id = s.id AND pp.phase_type = 'trial'
+	          AND pp.start_date <= CURRENT_DATE AND pp.end_date > CURRENT_DATE
+	    ) THEN 'trialing'
+	    ELSE 'active'
+	END`
+
+// categorySubtreeCondition отбирает подписки категории и всех ее подкатегорий;
+// параметр — номер аргумента с ID категории.
+const categorySubtreeCondition = `s.category_id IN (
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = $%d
+		UNION
+		SELECT c.id FROM categories c JOIN subtree t ON c.parent_id = t.id
+	)
+	SELECT id FROM subtree)`
+
+// subscriptionSelect выбирает подписки вместе с именами их тегов, статусом и
+// использованием на текущий месяц.
+const subscriptionSelect = `
+	SELECT s.*,
+	       ARRAY(
+	           SELECT t.name FROM subscription_tags st
+	           JOIN tags t ON t.id = st.tag_id
+	           WHERE st.subscription_id = s.id
+	           ORDER BY LOWER(t.name)
+	       ) AS tags,` + subscriptionStatus + ` AS status,
+	       su.last_used_at,
+	       COALESCE(um.uses, 0) AS uses_this_month,
+	       ROUND(s.price::NUMERIC / billing_interval_months(s.billing_interval) / NULLIF(um.uses, 0), 2) AS cost_per_use,
+	       COALESCE(su.last_used_at < CURRENT_DATE - 30, false) AS idle
+	FROM subscriptions s
+	LEFT JOIN subscription_usage su ON su.subscription_id = s.id
+	LEFT JOIN subscription_usage_monthly um
+	       ON um.subscription_id = s.id AND um.month = DATE_TRUNC('month', CURRENT_DATE)`
+
 func (r *subscriptionRepo) Create(ctx context.Context, sub *models.Subscription) error {
+	defer metrics.ObserveQuery("subscription", "Create", time.Now())
+
 	query := `
 		INSERT INTO subscriptions (
-			id, service_name, price, user_id, 
-			start_date, end_date, created_at, updated_at
+			id, service_name, service_id, category_id, price, user_id, 
+			split_rule, state, payment_method_id, billing_interval, start_date, end_date, created_at, updated_at
 		)
 		VALUES (
-			:id, :service_name, :price, :user_id, 
-			:start_date, :end_date, :created_at, :updated_at
+			:id, :service_name, :service_id, :category_id, :price, :user_id, 
+			:split_rule, :state, :payment_method_id, :billing_interval, :start_date, :end_date, :created_at, :updated_at
 		)`
 
 	sub.ID = uuid.New()
 	sub.CreatedAt = time.Now()
 	sub.UpdatedAt = time.Now()
 
-	_, err := r.db.NamedExecContext(ctx, query, sub)
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "Create",
+		"subscription_id": sub.ID.String(),
+	}).Debug("Inserting subscription")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, sub); err != nil {
+			return err
+		}
+		return replaceSubscriptionTags(ctx, tx, sub.ID, sub.Tags)
+	})
+	err = mapConstraintError(err)
+	tracing.End(span, err)
 	return err
 }
 
 func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	defer metrics.ObserveQuery("subscription", "GetByID", time.Now())
+
 	var sub models.Subscription
-	query := `SELECT * FROM subscriptions WHERE id = $1`
+	query := subscriptionSelect + ` WHERE s.id = $1`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "GetByID",
+		"subscription_id": id.String(),
+	}).Debug("Selecting subscription by id")
+
 	err := r.db.GetContext(ctx, &sub, query, id)
+	tracing.End(span, err)
 	return &sub, err
 }
 
 func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdateSubscriptionRequest) error {
+	defer metrics.ObserveQuery("subscription", "Update", time.Now())
+
 	query := "UPDATE subscriptions SET updated_at = $1"
 	args := []interface{}{time.Now()}
 	argIndex := 2
 
 	if update.ServiceName != nil {
-		query += fmt.Sprintf(", service_name = $%d", argIndex)
-		args = append(args, *update.ServiceName)
-		argIndex++
+		query += fmt.Sprintf(", service_name = $%d, service_id = $%d", argIndex, argIndex+1)
+		args = append(args, *update.ServiceName, update.ServiceID)
+		argIndex += 2
 	}
 
 	if update.Price != nil {
@@ -72,6 +167,12 @@ func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, update *mod
 		argIndex++
 	}
 
+	if update.BillingInterval != nil {
+		query += fmt.Sprintf(", billing_interval = $%d", argIndex)
+		args = append(args, *update.BillingInterval)
+		argIndex++
+	}
+
 	if update.EndDate != nil {
 		if *update.EndDate == "" {
 			query += fmt.Sprintf(", end_date = $%d", argIndex)
@@ -84,52 +185,469 @@ func (r *subscriptionRepo) Update(ctx context.Context, id uuid.UUID, update *mod
 		argIndex++
 	}
 
+	if update.CategoryID != nil {
+		query += fmt.Sprintf(", category_id = $%d", argIndex)
+		args = append(args, update.CategoryUUID)
+		argIndex++
+	}
+
+	if update.PaymentMethodID != nil {
+		query += fmt.Sprintf(", payment_method_id = $%d", argIndex)
+		args = append(args, update.PaymentMethodUUID)
+		argIndex++
+	}
+
+	if update.Unused != nil {
+		query += fmt.Sprintf(", unused = $%d", argIndex)
+		args = append(args, *update.Unused)
+		argIndex++
+	}
+
 	query += " WHERE id = $" + fmt.Sprint(argIndex)
 	args = append(args, id)
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "Update",
+		"subscription_id": id.String(),
+		"args_count":      len(args),
+	}).Debug("Updating subscription")
+
+	// Изменение цены или интервала оплаты записывается в историю вместе с
+	// обновлением, если изменилась цена в пересчете на месяц: по истории
+	// ищутся резкие повышения цен. Смена статуса из-за end_date записывается
+	// переходом.
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var status string
+		if update.EndDate != nil {
+			var err error
+			if status, err = lockStatus(ctx, tx, id); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		pricing := update.Price != nil || update.BillingInterval != nil
+		var old struct {
+			Price           int    `db:"price"`
+			BillingInterval string `db:"billing_interval"`
+		}
+		if pricing {
+			err := tx.GetContext(ctx, &old, `SELECT price, billing_interval FROM subscriptions WHERE id = $1 FOR UPDATE`, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		if update.EndDate != nil {
+			if err := recordStatusChange(ctx, tx, id, status); err != nil {
+				return err
+			}
+		}
+		if !pricing {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_price_changes
+				(subscription_id, old_price, new_price, old_billing_interval, new_billing_interval, changed_at)
+			SELECT s.id, $2, s.price, $3, s.billing_interval, $4
+			FROM subscriptions s
+			WHERE s.id = $1
+			  AND s.price::NUMERIC / billing_interval_months(s.billing_interval)
+			      <> $2::NUMERIC / billing_interval_months($3)`, id, old.Price, old.BillingInterval, args[0])
+		return err
+	})
+	err = mapConstraintError(err)
+	tracing.End(span, err)
 	return err
 }
 
 func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("subscription", "Delete", time.Now())
+
 	query := `DELETE FROM subscriptions WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "Delete",
+		"subscription_id": id.String(),
+	}).Debug("Deleting subscription")
+
 	_, err := r.db.ExecContext(ctx, query, id)
+	tracing.End(span, err)
 	return err
 }
 
-func (r *subscriptionRepo) List(ctx context.Context, limit, offset int) ([]models.Subscription, error) {
+func (r *subscriptionRepo) List(ctx context.Context, filter models.SubscriptionFilter) ([]models.Subscription, error) {
+	defer metrics.ObserveQuery("subscription", "List", time.Now())
+
 	var subscriptions []models.Subscription
-	query := `SELECT * FROM subscriptions ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	err := r.db.SelectContext(ctx, &subscriptions, query, limit, offset)
+	query := subscriptionSelect
+	args := []interface{}{}
+	conditions := []string{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("s.user_id = $%d", len(args)))
+	}
+
+	if filter.CategoryID != nil {
+		args = append(args, *filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf(categorySubtreeCondition, len(args)))
+	}
+
+	if filter.Tag != nil {
+		args = append(args, *filter.Tag)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM subscription_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE st.subscription_id = s.id AND LOWER(t.name) = LOWER($%d))`, len(args)))
+	}
+
+	if filter.PaymentMethodID != nil {
+		args = append(args, *filter.PaymentMethodID)
+		conditions = append(conditions, fmt.Sprintf("s.payment_method_id = $%d", len(args)))
+	}
+
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", subscriptionStatus, len(args)))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY s.created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "subscription",
+		"method":     "List",
+		"limit":      filter.Limit,
+		"offset":     filter.Offset,
+		"conditions": len(conditions),
+	}).Debug("Selecting subscriptions")
+
+	err := r.db.SelectContext(ctx, &subscriptions, query, args...)
+	tracing.End(span, err)
 	return subscriptions, err
 }
 
 func (r *subscriptionRepo) GetSummary(ctx context.Context, req models.SummaryRequest) (int, error) {
+	defer metrics.ObserveQuery("subscription", "GetSummary", time.Now())
+
+	totalCost, err := rollupSummary(ctx, r.db, req)
+	if err == nil {
+		metrics.SummaryReadsTotal.WithLabelValues("rollup").Inc()
+		return totalCost, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"repository": "subscription",
+			"method":     "GetSummary",
+			"error":      err.Error(),
+		}).Warn("Failed to read summary from rollups, falling back to subscriptions")
+	}
+	metrics.SummaryReadsTotal.WithLabelValues("raw").Inc()
+
+	from, cost := summarySource(req)
+	where, args := summaryConditions(req)
+	query := `SELECT COALESCE(ROUND(SUM(` + cost + `)), 0)::INTEGER FROM ` + from + ` WHERE ` + where
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "GetSummary", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "subscription",
+		"method":     "GetSummary",
+		"args_count": len(args),
+	}).Debug("Calculating subscription summary")
+
+	err = r.db.GetContext(ctx, &totalCost, query, args...)
+	tracing.End(span, err)
+	return totalCost, err
+}
+
+// GetSummaryGroups считает стоимость в разрезе категорий верхнего уровня или
+// тегов. Подписка с несколькими тегами учитывается в каждом из них.
+func (r *subscriptionRepo) GetSummaryGroups(ctx context.Context, req models.SummaryRequest) ([]models.SummaryGroup, error) {
+	defer metrics.ObserveQuery("subscription", "GetSummaryGroups", time.Now())
+
+	from, cost := summarySource(req)
+	where, args := summaryConditions(req)
+
+	var query string
+	switch *req.GroupBy {
+	case models.SummaryGroupByCategory:
+		query = `
+			WITH RECURSIVE category_roots AS (
+				SELECT id, id AS root_id, name AS root_name FROM categories WHERE parent_id IS NULL
+				UNION ALL
+				SELECT c.id, r.root_id, r.root_name
+				FROM categories c JOIN category_roots r ON c.parent_id = r.id
+			)
+			SELECT r.root_id AS id,
+			       COALESCE(r.root_name, 'uncategorized') AS name,
+			       COALESCE(ROUND(SUM(` + cost + `)), 0)::INTEGER AS total_cost
+			FROM ` + from + `
+			LEFT JOIN category_roots r ON r.id = s.category_id
+			WHERE ` + where + `
+			GROUP BY r.root_id, r.root_name
+			ORDER BY total_cost DESC`
+	case models.SummaryGroupByTag:
+		query = `
+			SELECT t.id,
+			       COALESCE(t.name, 'untagged') AS name,
+			       COALESCE(ROUND(SUM(` + cost + `)), 0)::INTEGER AS total_cost
+			FROM ` + from + `
+			LEFT JOIN subscription_tags st ON st.subscription_id = s.id
+			LEFT JOIN tags t ON t.id = st.tag_id
+			WHERE ` + where + `
+			GROUP BY t.id, t.name
+			ORDER BY total_cost DESC`
+	default:
+		return nil, fmt.Errorf("unsupported summary grouping %q", *req.GroupBy)
+	}
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "GetSummaryGroups", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "subscription",
+		"method":     "GetSummaryGroups",
+		"group_by":   *req.GroupBy,
+	}).Debug("Calculating grouped subscription summary")
+
+	var groups []models.SummaryGroup
+	err := r.db.SelectContext(ctx, &groups, query, args...)
+	tracing.End(span, err)
+	return groups, err
+}
+
+// summarySource возвращает источник строк сводки и выражение стоимости.
+// Стоимость считается помесячно через subscription_monthly_charges (алиас c)
+// с ценой активной фазы; границы периода — параметры $2 и $1 из
+// summaryConditions. Сводка по пользователю считается через
+// subscription_shares (алиас sh): учитывается только его доля, в том числе
+// в общих подписках других пользователей.
+func summarySource(req models.SummaryRequest) (from, cost string) {
+	from = "subscription_monthly_charges($2, $1) c JOIN subscriptions s ON s.id = c.subscription_id"
+	if req.UserID != nil {
+		return from + " JOIN subscription_shares sh ON sh.subscription_id = s.id", "c.amount * sh.share_ratio"
+	}
+	return from, "c.amount"
+}
+
+// summaryConditions строит условие WHERE для подписок (алиас s), активных
+// в периоде запроса и подходящих под фильтры.
+func summaryConditions(req models.SummaryRequest) (string, []interface{}) {
 	startDate, _ := time.Parse("01-2006", req.StartDate)
 	endDate, _ := time.Parse("01-2006", req.EndDate)
 
-	query := `SELECT COALESCE(SUM(price), 0) FROM subscriptions 
-	          WHERE start_date <= $1 AND (end_date IS NULL OR end_date >= $2)`
+	conditions := []string{"s.start_date <= $1", "(s.end_date IS NULL OR s.end_date >= $2)"}
 	args := []interface{}{endDate, startDate}
 
-	conditions := []string{}
-
 	if req.UserID != nil {
 		userID, _ := uuid.Parse(*req.UserID)
-		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)+1))
+		conditions = append(conditions, fmt.Sprintf("sh.user_id = $%d", len(args)+1))
 		args = append(args, userID)
 	}
 
-	if req.ServiceName != nil {
-		conditions = append(conditions, fmt.Sprintf("service_name = $%d", len(args)+1))
+	if req.ServiceID != nil {
+		conditions = append(conditions, fmt.Sprintf("s.service_id = $%d", len(args)+1))
+		args = append(args, *req.ServiceID)
+	} else if req.ServiceName != nil {
+		conditions = append(conditions, fmt.Sprintf("s.service_name = $%d", len(args)+1))
 		args = append(args, *req.ServiceName)
 	}
 
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
+	if req.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf(categorySubtreeCondition, len(args)+1))
+		args = append(args, *req.CategoryID)
 	}
 
-	var totalCost int
-	err := r.db.GetContext(ctx, &totalCost, query, args...)
-	return totalCost, err
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *subscriptionRepo) GetServiceStats(ctx context.Context) ([]models.ServiceStats, error) {
+	defer metrics.ObserveQuery("subscription", "GetServiceStats", time.Now())
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Подписка считается действующей в текущем месяце независимо от того,
+	// приходится ли на него списание, а расход приводится к месяцу по
+	// интервалу оплаты: годовые подписки не пропадают из метрик между оплатами.
+	query := `
+		SELECT s.service_name,
+		       COUNT(*) AS active_subscriptions,
+		       COALESCE(ROUND(SUM(s.price::NUMERIC / billing_interval_months(s.billing_interval))), 0)::INTEGER AS monthly_spend
+		FROM subscriptions s
+		WHERE s.start_date <= $1
+		  AND (s.end_date IS NULL OR s.end_date >= $1)
+		  AND s.state <> 'cancelled'
+		GROUP BY s.service_name`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "GetServiceStats", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "subscription",
+		"method":     "GetServiceStats",
+	}).Debug("Calculating per-service statistics")
+
+	var stats []models.ServiceStats
+	err := r.db.SelectContext(ctx, &stats, query, currentMonth)
+	tracing.End(span, err)
+	return stats, err
+}
+
+func (r *subscriptionRepo) ChangeState(ctx context.Context, id uuid.UUID, change *models.StateChange) error {
+	defer metrics.ObserveQuery("subscription", "ChangeState", time.Now())
+
+	query := `
+		UPDATE subscriptions
+		SET state = $1, end_date = $2, status_changed_at = $3, cancelled_at = $4, updated_at = $3
+		WHERE id = $5`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "ChangeState", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "ChangeState",
+		"subscription_id": id.String(),
+		"from_status":     change.Transition.FromStatus,
+		"to_status":       change.Transition.ToStatus,
+	}).Debug("Changing subscription state")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var current struct {
+			State   string     `db:"state"`
+			EndDate *time.Time `db:"end_date"`
+		}
+		if err := tx.GetContext(ctx, &current, `SELECT state, end_date FROM subscriptions WHERE id = $1 FOR UPDATE`, id); err != nil {
+			return err
+		}
+		if current.State != change.FromState || !sameDate(current.EndDate, change.FromEndDate) {
+			return ErrStaleState
+		}
+
+		if change.ResumeFrom != nil && change.FromEndDate != nil {
+			if err := closeGapWithPause(ctx, tx, id, change.FromEndDate.AddDate(0, 1, 0), *change.ResumeFrom); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		var cancelledAt *time.Time
+		if change.State == models.StatusCancelled {
+			cancelledAt = &now
+		}
+		if _, err := tx.ExecContext(ctx, query, change.State, change.EndDate, now, cancelledAt, id); err != nil {
+			return err
+		}
+
+		change.Transition.ID = uuid.New()
+		change.Transition.SubscriptionID = id
+		change.Transition.CreatedAt = now
+		_, err := tx.NamedExecContext(ctx, `
+			INSERT INTO subscription_transitions (id, subscription_id, from_status, to_status, created_at)
+			VALUES (:id, :subscription_id, :from_status, :to_status, :created_at)`, change.Transition)
+		return err
+	})
+	tracing.End(span, err)
+	return err
+}
+
+// closeGapWithPause не дает начислить месяцы между окончанием подписки и ее
+// возобновлением: если промежуток [gapStart, resumeFrom) не пуст, паузы после
+// gapStart удаляются или обрезаются, а сам промежуток закрывается новой паузой.
+func closeGapWithPause(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, gapStart, resumeFrom time.Time) error {
+	if !gapStart.Before(resumeFrom) {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM subscription_pauses WHERE subscription_id = $1 AND start_date >= $2`,
+		id, gapStart); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE subscription_pauses SET end_date = $2, updated_at = $3
+		WHERE subscription_id = $1 AND (end_date IS NULL OR end_date > $2)`,
+		id, gapStart, time.Now()); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO subscription_pauses (id, subscription_id, start_date, end_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)`,
+		uuid.New(), id, gapStart, resumeFrom, time.Now())
+	return err
+}
+
+// lockStatus блокирует подписку и возвращает ее текущий статус. Вместе с
+// recordStatusChange записывает переходы, которые происходят не через ChangeState.
+func lockStatus(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (string, error) {
+	var status string
+	err := tx.GetContext(ctx, &status,
+		`SELECT `+subscriptionStatus+` FROM subscriptions s WHERE s.id = $1 FOR UPDATE`, id)
+	return status, err
+}
+
+// recordStatusChange записывает переход и status_changed_at, если статус
+// подписки после изменения отличается от from.
+func recordStatusChange(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, from string) error {
+	var to string
+	if err := tx.GetContext(ctx, &to,
+		`SELECT `+subscriptionStatus+` FROM subscriptions s WHERE s.id = $1`, id); err != nil {
+		return err
+	}
+	if to == from {
+		return nil
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE subscriptions SET status_changed_at = $2 WHERE id = $1`, id, now); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO subscription_transitions (id, subscription_id, from_status, to_status, created_at)
+		VALUES ($1, $2, $3, $4, $5)`, uuid.New(), id, from, to, now)
+	return err
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func (r *subscriptionRepo) ListTransitions(ctx context.Context, id uuid.UUID) ([]models.SubscriptionTransition, error) {
+	defer metrics.ObserveQuery("subscription", "ListTransitions", time.Now())
+
+	var transitions []models.SubscriptionTransition
+	query := `SELECT * FROM subscription_transitions WHERE subscription_id = $1 ORDER BY created_at, id`
+
+	ctx, span := startSpan(ctx, "SubscriptionRepository", "ListTransitions", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "subscription",
+		"method":          "ListTransitions",
+		"subscription_id": id.String(),
+	}).Debug("Selecting subscription transitions")
+
+	err := r.db.SelectContext(ctx, &transitions, query, id)
+	tracing.End(span, err)
+	return transitions, err
 }
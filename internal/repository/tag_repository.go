@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, tag *models.Tag) error
+	List(ctx context.Context) ([]models.Tag, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SetForSubscription заменяет набор тегов подписки, создавая недостающие теги.
+	SetForSubscription(ctx context.Context, subscriptionID uuid.UUID, names []string) error
+}
+
+type tagRepo struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) TagRepository {
+	return &tagRepo{db: db}
+}
+
+func (r *tagRepo) Create(ctx context.Context, tag *models.Tag) error {
+	defer metrics.ObserveQuery("tag", "Create", time.Now())
+
+	query := `INSERT INTO tags (id, name, created_at) VALUES (:id, :name, :created_at)`
+
+	tag.ID = uuid.New()
+	tag.CreatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "TagRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "tag",
+		"method":     "Create",
+		"tag_id":     tag.ID.String(),
+	}).Debug("Inserting tag")
+
+	_, err := r.db.NamedExecContext(ctx, query, tag)
+	tracing.End(span, err)
+	return mapConstraintError(err)
+}
+
+func (r *tagRepo) List(ctx context.Context) ([]models.Tag, error) {
+	defer metrics.ObserveQuery("tag", "List", time.Now())
+
+	var tags []models.Tag
+	query := `SELECT * FROM tags ORDER BY LOWER(name)`
+
+	ctx, span := startSpan(ctx, "TagRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "tag",
+		"method":     "List",
+	}).Debug("Selecting tags")
+
+	err := r.db.SelectContext(ctx, &tags, query)
+	tracing.End(span, err)
+	return tags, err
+}
+
+func (r *tagRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("tag", "Delete", time.Now())
+
+	query := `DELETE FROM tags WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "TagRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "tag",
+		"method":     "Delete",
+		"tag_id":     id.String(),
+	}).Debug("Deleting tag")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *tagRepo) SetForSubscription(ctx context.Context, subscriptionID uuid.UUID, names []string) error {
+	defer metrics.ObserveQuery("tag", "SetForSubscription", time.Now())
+
+	ctx, span := startSpan(ctx, "TagRepository", "SetForSubscription", replaceSubscriptionTagsQuery)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "tag",
+		"method":          "SetForSubscription",
+		"subscription_id": subscriptionID.String(),
+		"tags":            len(names),
+	}).Debug("Replacing subscription tags")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, `SELECT true FROM subscriptions WHERE id = $1 FOR UPDATE`, subscriptionID); err != nil {
+			return err
+		}
+		return replaceSubscriptionTags(ctx, tx, subscriptionID, names)
+	})
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+const replaceSubscriptionTagsQuery = `
+	WITH upserted AS (
+		INSERT INTO tags (name)
+		SELECT DISTINCT ON (LOWER(input.name)) input.name FROM UNNEST($2::TEXT[]) AS input(name)
+		ON CONFLICT ((LOWER(name))) DO UPDATE SET name = tags.name
+		RETURNING id
+	)
+	INSERT INTO subscription_tags (subscription_id, tag_id)
+	SELECT $1, id FROM upserted`
+
+// replaceSubscriptionTags используется и при создании подписки, чтобы теги
+// сохранялись в одной транзакции с ней.
+func replaceSubscriptionTags(ctx context.Context, tx *sqlx.Tx, subscriptionID uuid.UUID, names []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscription_tags WHERE subscription_id = $1`, subscriptionID); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, replaceSubscriptionTagsQuery, subscriptionID, pq.Array(names))
+	return err
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type TimelineRepository interface {
+	// Subscriptions возвращает подписки, в которых участвует пользователь,
+	// включая общие подписки других пользователей.
+	Subscriptions(ctx context.Context, userID uuid.UUID) ([]models.TimelineSubscription, error)
+	// Charges возвращает долю пользователя в списаниях за месяцы [from, to]
+	// по тем же правилам, что и сводка.
+	Charges(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.TimelineCharge, error)
+}
+
+type timelineRepo struct {
+	db *sqlx.DB
+}
+
+func NewTimelineRepository(db *sqlx.DB) TimelineRepository {
+	return &timelineRepo{db: db}
+}
+
+func (r *timelineRepo) Subscriptions(ctx context.Context, userID uuid.UUID) ([]models.TimelineSubscription, error) {
+	defer metrics.ObserveQuery("timeline", "Subscriptions", time.Now())
+
+	query := `
+		SELECT s.id, s.service_id, s.service_name, s.price, s.billing_interval,
+		       s.start_date, s.end_date
+		FROM subscriptions s
+		JOIN subscription_shares sh ON sh.subscription_id = s.id
+		WHERE sh.user_id = $1
+		ORDER BY LOWER(s.service_name), s.start_date, s.created_at`
+
+	ctx, span := startSpan(ctx, "TimelineRepository", "Subscriptions", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "timeline",
+		"method":     "Subscriptions",
+		"user_id":    userID.String(),
+	}).Debug("Selecting user subscriptions for timeline")
+
+	var subscriptions []models.TimelineSubscription
+	err := r.db.SelectContext(ctx, &subscriptions, query, userID)
+	tracing.End(span, err)
+	return subscriptions, err
+}
+
+func (r *timelineRepo) Charges(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.TimelineCharge, error) {
+	defer metrics.ObserveQuery("timeline", "Charges", time.Now())
+
+	query := `
+		SELECT c.subscription_id, c.charge_month AS month, c.amount * sh.share_ratio AS amount
+		FROM subscription_monthly_charges($2, $3) c
+		JOIN subscription_shares sh ON sh.subscription_id = c.subscription_id
+		WHERE sh.user_id = $1
+		ORDER BY c.charge_month`
+
+	ctx, span := startSpan(ctx, "TimelineRepository", "Charges", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "timeline",
+		"method":     "Charges",
+		"user_id":    userID.String(),
+		"from":       from.Format("2006-01"),
+		"to":         to.Format("2006-01"),
+	}).Debug("Selecting user charges for timeline")
+
+	var charges []models.TimelineCharge
+	err := r.db.SelectContext(ctx, &charges, query, userID, from, to)
+	tracing.End(span, err)
+	return charges, err
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"subscribe_project/internal/tracing"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan открывает клиентский спан запроса к базе с текстом SQL в атрибутах.
+func startSpan(ctx context.Context, repository, method, query string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, repository+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(method),
+			semconv.DBQueryText(query),
+		),
+	)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type UsageRepository interface {
+	// Record добавляет count использований в месяц usedAt и сдвигает время
+	// последнего использования, если usedAt позже.
+	Record(ctx context.Context, subscriptionID uuid.UUID, usedAt time.Time, count int) error
+	// Get возвращает использование подписки с помесячной историей за [from, to].
+	// Месяцы без использований в истории отсутствуют.
+	Get(ctx context.Context, subscriptionID uuid.UUID, from, to time.Time) (*models.SubscriptionUsage, error)
+	// SummaryUses считает использования подписок сводки за ее период. В сводке
+	// по пользователю использования общих подписок берутся по его доле, как и
+	// стоимость.
+	SummaryUses(ctx context.Context, req models.SummaryRequest) (float64, error)
+}
+
+type usageRepo struct {
+	db *sqlx.DB
+}
+
+func NewUsageRepository(db *sqlx.DB) UsageRepository {
+	return &usageRepo{db: db}
+}
+
+func (r *usageRepo) Record(ctx context.Context, subscriptionID uuid.UUID, usedAt time.Time, count int) error {
+	defer metrics.ObserveQuery("usage", "Record", time.Now())
+
+	query := `
+		INSERT INTO subscription_usage (subscription_id, last_used_at, total_uses)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id) DO UPDATE
+		SET last_used_at = GREATEST(subscription_usage.last_used_at, EXCLUDED.last_used_at),
+		    total_uses = subscription_usage.total_uses + EXCLUDED.total_uses`
+
+	ctx, span := startSpan(ctx, "UsageRepository", "Record", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "usage",
+		"method":          "Record",
+		"subscription_id": subscriptionID.String(),
+		"count":           count,
+	}).Debug("Recording subscription usage")
+
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, subscriptionID, usedAt, count); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO subscription_usage_monthly (subscription_id, month, uses)
+			VALUES ($1, DATE_TRUNC('month', $2::TIMESTAMP)::DATE, $3)
+			ON CONFLICT (subscription_id, month) DO UPDATE
+			SET uses = subscription_usage_monthly.uses + EXCLUDED.uses`, subscriptionID, usedAt, count)
+		return err
+	})
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *usageRepo) Get(ctx context.Context, subscriptionID uuid.UUID, from, to time.Time) (*models.SubscriptionUsage, error) {
+	defer metrics.ObserveQuery("usage", "Get", time.Now())
+
+	query := `
+		SELECT um.month, um.uses,
+		       ROUND(s.price::NUMERIC / billing_interval_months(s.billing_interval) / NULLIF(um.uses, 0), 2) AS cost_per_use
+		FROM subscription_usage_monthly um
+		JOIN subscriptions s ON s.id = um.subscription_id
+		WHERE um.subscription_id = $1 AND um.month BETWEEN $2 AND $3
+		ORDER BY um.month`
+
+	ctx, span := startSpan(ctx, "UsageRepository", "Get", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository":      "usage",
+		"method":          "Get",
+		"subscription_id": subscriptionID.String(),
+	}).Debug("Selecting subscription usage")
+
+	usage := &models.SubscriptionUsage{SubscriptionID: subscriptionID}
+	err := r.db.SelectContext(ctx, &usage.Months, query, subscriptionID, from, to)
+	if err == nil {
+		err = r.db.GetContext(ctx, usage,
+			`SELECT subscription_id, last_used_at, total_uses FROM subscription_usage WHERE subscription_id = $1`, subscriptionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	}
+	tracing.End(span, err)
+	return usage, err
+}
+
+func (r *usageRepo) SummaryUses(ctx context.Context, req models.SummaryRequest) (float64, error) {
+	defer metrics.ObserveQuery("usage", "SummaryUses", time.Now())
+
+	from := "subscription_usage_monthly um JOIN subscriptions s ON s.id = um.subscription_id"
+	uses := "um.uses"
+	if req.UserID != nil {
+		from += " JOIN subscription_shares sh ON sh.subscription_id = s.id"
+		uses = "um.uses * sh.share_ratio"
+	}
+	where, args := summaryConditions(req)
+	query := `SELECT COALESCE(SUM(` + uses + `), 0)::FLOAT8 FROM ` + from + `
+		WHERE um.month BETWEEN $2 AND $1 AND ` + where
+
+	ctx, span := startSpan(ctx, "UsageRepository", "SummaryUses", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "usage",
+		"method":     "SummaryUses",
+		"args_count": len(args),
+	}).Debug("Counting subscription usage for summary")
+
+	var total float64
+	err := r.db.GetContext(ctx, &total, query, args...)
+	tracing.End(span, err)
+	return total, err
+}
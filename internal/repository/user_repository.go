@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	// GetByID возвращает только не удаленных пользователей.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	Update(ctx context.Context, id uuid.UUID, update *models.UpdateUserRequest) error
+	// SoftDelete помечает пользователя удаленным, завершает его открытые
+	// подписки текущим месяцем, удаляет еще не начавшиеся и исключает его из
+	// участников чужих подписок. Возвращает число завершенных и удаленных подписок.
+	SoftDelete(ctx context.Context, id uuid.UUID) (endedSubscriptions int64, err error)
+	// Delete удаляет пользователя вместе с подписками.
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, limit, offset int) ([]models.User, error)
+}
+
+type userRepo struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) UserRepository {
+	return &userRepo{db: db}
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	defer metrics.ObserveQuery("user", "Create", time.Now())
+
+	query := `
+		INSERT INTO users (id, name, email, timezone, preferences, created_at, updated_at)
+		VALUES (:id, :name, :email, :timezone, :preferences, :created_at, :updated_at)`
+
+	user.ID = uuid.New()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	ctx, span := startSpan(ctx, "UserRepository", "Create", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "Create",
+		"user_id":    user.ID.String(),
+	}).Debug("Inserting user")
+
+	_, err := r.db.NamedExecContext(ctx, query, user)
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer metrics.ObserveQuery("user", "GetByID", time.Now())
+
+	var user models.User
+	query := `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	ctx, span := startSpan(ctx, "UserRepository", "GetByID", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "GetByID",
+		"user_id":    id.String(),
+	}).Debug("Selecting user by id")
+
+	err := r.db.GetContext(ctx, &user, query, id)
+	tracing.End(span, err)
+	return &user, err
+}
+
+func (r *userRepo) Update(ctx context.Context, id uuid.UUID, update *models.UpdateUserRequest) error {
+	defer metrics.ObserveQuery("user", "Update", time.Now())
+
+	query := "UPDATE users SET updated_at = $1"
+	args := []interface{}{time.Now()}
+	argIndex := 2
+
+	if update.Name != nil {
+		query += fmt.Sprintf(", name = $%d", argIndex)
+		args = append(args, *update.Name)
+		argIndex++
+	}
+
+	if update.Email != nil {
+		query += fmt.Sprintf(", email = $%d", argIndex)
+		if *update.Email == "" {
+			args = append(args, nil)
+		} else {
+			args = append(args, *update.Email)
+		}
+		argIndex++
+	}
+
+	if update.Timezone != nil {
+		query += fmt.Sprintf(", timezone = $%d", argIndex)
+		args = append(args, *update.Timezone)
+		argIndex++
+	}
+
+	if len(update.Preferences) > 0 {
+		query += fmt.Sprintf(", preferences = $%d", argIndex)
+		args = append(args, string(update.Preferences))
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_at IS NULL", argIndex)
+	args = append(args, id)
+
+	ctx, span := startSpan(ctx, "UserRepository", "Update", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "Update",
+		"user_id":    id.String(),
+		"args_count": len(args),
+	}).Debug("Updating user")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	err = mapConstraintError(err)
+	tracing.End(span, err)
+	return err
+}
+
+func (r *userRepo) SoftDelete(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer metrics.ObserveQuery("user", "SoftDelete", time.Now())
+
+	query := `UPDATE users SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	endQuery := `
+		UPDATE subscriptions
+		SET end_date = DATE_TRUNC('month', CURRENT_DATE)::DATE, updated_at = $1
+		WHERE user_id = $2 AND start_date <= DATE_TRUNC('month', CURRENT_DATE)
+		  AND (end_date IS NULL OR end_date > DATE_TRUNC('month', CURRENT_DATE))`
+	// Не начавшиеся подписки не оплачивались ни разу, поэтому удаляются.
+	futureQuery := `
+		DELETE FROM subscriptions
+		WHERE user_id = $1 AND start_date > DATE_TRUNC('month', CURRENT_DATE)`
+
+	ctx, span := startSpan(ctx, "UserRepository", "SoftDelete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "SoftDelete",
+		"user_id":    id.String(),
+	}).Debug("Soft deleting user")
+
+	var ended int64
+	now := time.Now()
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, now, id)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		result, err = tx.ExecContext(ctx, endQuery, now, id)
+		if err != nil {
+			return err
+		}
+		if ended, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		result, err = tx.ExecContext(ctx, futureQuery, id)
+		if err != nil {
+			return err
+		}
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		ended += removed
+
+		// Доля удаленного пользователя в чужих подписках переходит к владельцу.
+		_, err = tx.ExecContext(ctx, `DELETE FROM subscription_members WHERE user_id = $1`, id)
+		return err
+	})
+	tracing.End(span, err)
+	return ended, err
+}
+
+func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("user", "Delete", time.Now())
+
+	query := `DELETE FROM users WHERE id = $1`
+
+	ctx, span := startSpan(ctx, "UserRepository", "Delete", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "Delete",
+		"user_id":    id.String(),
+	}).Debug("Deleting user")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err == nil {
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			err = sql.ErrNoRows
+		}
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (r *userRepo) List(ctx context.Context, limit, offset int) ([]models.User, error) {
+	defer metrics.ObserveQuery("user", "List", time.Now())
+
+	var users []models.User
+	query := `SELECT * FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	ctx, span := startSpan(ctx, "UserRepository", "List", query)
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"repository": "user",
+		"method":     "List",
+		"limit":      limit,
+		"offset":     offset,
+	}).Debug("Selecting users")
+
+	err := r.db.SelectContext(ctx, &users, query, limit, offset)
+	tracing.End(span, err)
+	return users, err
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAnalyticsMonths = 12
+	maxAnalyticsMonths     = 60
+)
+
+type AnalyticsService interface {
+	MRR(ctx context.Context, req models.AnalyticsRequest) ([]models.MRRPoint, error)
+	SubscriptionFlow(ctx context.Context, req models.AnalyticsRequest) ([]models.SubscriptionFlowPoint, error)
+	Churn(ctx context.Context, req models.AnalyticsRequest) ([]models.ServiceChurn, error)
+}
+
+type analyticsService struct {
+	repo    repository.AnalyticsRepository
+	catalog repository.CatalogRepository
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, catalog repository.CatalogRepository) AnalyticsService {
+	logger.Log.WithField("component", "analytics_service").Info("Creating new analytics service")
+	return &analyticsService{repo: repo, catalog: catalog}
+}
+
+func (s *analyticsService) MRR(ctx context.Context, req models.AnalyticsRequest) (result []models.MRRPoint, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "AnalyticsService.MRR")
+	defer func() { tracing.End(span, err) }()
+
+	filter, rows, err := s.monthlyMetrics(ctx, req, "MRR")
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]models.MRRPoint, analyticsMonths(filter))
+	for i := range points {
+		points[i].Month = filter.From.AddDate(0, i, 0)
+	}
+	for _, row := range rows {
+		point := &points[analyticsIndex(filter, row.Month)]
+		point.MRR += row.MRR
+		point.ActiveSubscriptions += row.Active
+	}
+
+	return points, nil
+}
+
+func (s *analyticsService) SubscriptionFlow(ctx context.Context, req models.AnalyticsRequest) (result []models.SubscriptionFlowPoint, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "AnalyticsService.SubscriptionFlow")
+	defer func() { tracing.End(span, err) }()
+
+	filter, rows, err := s.monthlyMetrics(ctx, req, "SubscriptionFlow")
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]models.SubscriptionFlowPoint, analyticsMonths(filter))
+	for i := range points {
+		points[i].Month = filter.From.AddDate(0, i, 0)
+	}
+	for _, row := range rows {
+		point := &points[analyticsIndex(filter, row.Month)]
+		point.New += row.New
+		point.Cancelled += row.Cancelled
+		point.Net += row.New - row.Cancelled
+	}
+
+	return points, nil
+}
+
+func (s *analyticsService) Churn(ctx context.Context, req models.AnalyticsRequest) (result []models.ServiceChurn, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "AnalyticsService.Churn")
+	defer func() { tracing.End(span, err) }()
+
+	filter, rows, err := s.monthlyMetrics(ctx, req, "Churn")
+	if err != nil {
+		return nil, err
+	}
+
+	months := analyticsMonths(filter)
+	byService := make(map[string]*models.ServiceChurn)
+	active := make(map[string]int)
+	cancelled := make(map[string]int)
+	for _, row := range rows {
+		churn, ok := byService[row.ServiceName]
+		if !ok {
+			churn = &models.ServiceChurn{ServiceName: row.ServiceName, Points: make([]models.ChurnPoint, months)}
+			for i := range churn.Points {
+				churn.Points[i].Month = filter.From.AddDate(0, i, 0)
+			}
+			byService[row.ServiceName] = churn
+		}
+
+		point := &churn.Points[analyticsIndex(filter, row.Month)]
+		point.Active = row.Active
+		point.Cancelled = row.Cancelled
+		point.ChurnRate = churnRate(row.Cancelled, row.Active)
+		active[row.ServiceName] += row.Active
+		cancelled[row.ServiceName] += row.Cancelled
+	}
+
+	result = make([]models.ServiceChurn, 0, len(byService))
+	for name, churn := range byService {
+		churn.ChurnRate = churnRate(cancelled[name], active[name])
+		result = append(result, *churn)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ServiceName < result[j].ServiceName
+	})
+
+	return result, nil
+}
+
+func (s *analyticsService) monthlyMetrics(ctx context.Context, req models.AnalyticsRequest, method string) (models.AnalyticsFilter, []models.ServiceMonthMetrics, error) {
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"has_start_date":   req.StartDate != nil,
+		"has_end_date":     req.EndDate != nil,
+		"has_service_name": req.ServiceName != nil,
+		"method":           method,
+	}).Info("Calculating subscription analytics")
+
+	filter, err := s.analyticsFilter(ctx, req)
+	if err != nil {
+		return filter, nil, err
+	}
+
+	rows, err := s.repo.MonthlyMetrics(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": method,
+		}).Error("Failed to get monthly metrics from repository")
+		return filter, nil, err
+	}
+
+	return filter, rows, nil
+}
+
+// analyticsFilter по умолчанию берет последние 12 месяцев, включая текущий.
+func (s *analyticsService) analyticsFilter(ctx context.Context, req models.AnalyticsRequest) (models.AnalyticsFilter, error) {
+	var filter models.AnalyticsFilter
+
+	to, err := parseMonthOrCurrent(req.EndDate, "end_date")
+	if err != nil {
+		return filter, err
+	}
+	from := to.AddDate(0, 1-defaultAnalyticsMonths, 0)
+	if req.StartDate != nil {
+		if from, err = parseMonthOrCurrent(req.StartDate, "start_date"); err != nil {
+			return filter, err
+		}
+	}
+	if to.Before(from) {
+		return filter, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidInput)
+	}
+	filter.From, filter.To = from, to
+	if analyticsMonths(filter) > maxAnalyticsMonths {
+		return filter, fmt.Errorf("%w: period must not exceed %d months", ErrInvalidInput, maxAnalyticsMonths)
+	}
+
+	if req.ServiceName != nil {
+		filter.ServiceName = req.ServiceName
+		service, err := resolveCatalogService(ctx, s.catalog, *req.ServiceName)
+		if err != nil {
+			return filter, err
+		}
+		if service != nil {
+			filter.ServiceID = &service.ID
+		}
+	}
+
+	return filter, nil
+}
+
+func analyticsMonths(filter models.AnalyticsFilter) int {
+	return analyticsIndex(filter, filter.To) + 1
+}
+
+func analyticsIndex(filter models.AnalyticsFilter, month time.Time) int {
+	return (month.Year()-filter.From.Year())*12 + int(month.Month()) - int(filter.From.Month())
+}
+
+func churnRate(cancelled, active int) float64 {
+	if active == 0 {
+		return 0
+	}
+	return math.Round(float64(cancelled)/float64(active)*10000) / 100
+}
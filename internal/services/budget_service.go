@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/notify"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// budgetThresholds — пороги расходов в процентах бюджета, о которых
+// отправляются уведомления (по возрастанию).
+var budgetThresholds = []int{80, 100}
+
+const alertBudgetThreshold = "budget_threshold"
+
+type BudgetService interface {
+	CreateBudget(ctx context.Context, req models.CreateBudgetRequest) (*models.Budget, error)
+	GetBudget(ctx context.Context, id string) (*models.Budget, error)
+	UpdateBudget(ctx context.Context, id string, req models.UpdateBudgetRequest) (*models.Budget, error)
+	DeleteBudget(ctx context.Context, id string) error
+	ListBudgets(ctx context.Context, userID *string) ([]models.Budget, error)
+	GetBudgetStatus(ctx context.Context, id string) (*models.BudgetStatus, error)
+	ListBudgetStatuses(ctx context.Context, userID string) ([]models.BudgetStatus, error)
+	// CheckBudgets пересчитывает все бюджеты и уведомляет о впервые
+	// достигнутых за период порогах. Возвращает число отправленных уведомлений.
+	CheckBudgets(ctx context.Context) (int, error)
+}
+
+type budgetService struct {
+	repo          repository.BudgetRepository
+	subscriptions repository.SubscriptionRepository
+	catalog       repository.CatalogRepository
+	categories    repository.CategoryRepository
+	users         repository.UserRepository
+	notifier      notify.Notifier
+}
+
+func NewBudgetService(repo repository.BudgetRepository, subscriptions repository.SubscriptionRepository, catalog repository.CatalogRepository, categories repository.CategoryRepository, users repository.UserRepository, notifier notify.Notifier) BudgetService {
+	logger.Log.WithField("component", "budget_service").Info("Creating new budget service")
+	return &budgetService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		catalog:       catalog,
+		categories:    categories,
+		users:         users,
+		notifier:      notifier,
+	}
+}
+
+func (s *budgetService) CreateBudget(ctx context.Context, req models.CreateBudgetRequest) (result *models.Budget, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.CreateBudget")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id": req.UserID,
+		"scope":   req.Scope,
+		"period":  req.Period,
+		"method":  "CreateBudget",
+	}).Info("Creating budget")
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user_id", ErrInvalidInput)
+	}
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalidInput, userID)
+		}
+		return nil, err
+	}
+
+	if err := validateBudgetPeriod(req.Period); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidInput)
+	}
+
+	budget := &models.Budget{
+		UserID:   userID,
+		Scope:    req.Scope,
+		Period:   req.Period,
+		Amount:   req.Amount,
+		Currency: models.DefaultCurrency,
+	}
+
+	if req.Currency != nil && *req.Currency != "" && !strings.EqualFold(*req.Currency, models.DefaultCurrency) {
+		return nil, fmt.Errorf("%w: only %s budgets are supported", ErrInvalidInput, models.DefaultCurrency)
+	}
+
+	switch req.Scope {
+	case models.BudgetScopeUser:
+		if req.CategoryID != nil || req.ServiceName != nil {
+			return nil, fmt.Errorf("%w: user budget must not have category_id or service_name", ErrInvalidInput)
+		}
+	case models.BudgetScopeCategory:
+		if req.CategoryID == nil || req.ServiceName != nil {
+			return nil, fmt.Errorf("%w: category budget requires category_id only", ErrInvalidInput)
+		}
+		categoryID, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid category_id", ErrInvalidInput)
+		}
+		if _, err := s.categories.GetByID(ctx, categoryID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: category %s does not exist", ErrInvalidInput, categoryID)
+			}
+			return nil, err
+		}
+		budget.CategoryID = &categoryID
+	case models.BudgetScopeService:
+		if req.ServiceName == nil || req.CategoryID != nil {
+			return nil, fmt.Errorf("%w: service budget requires service_name only", ErrInvalidInput)
+		}
+		name := strings.TrimSpace(*req.ServiceName)
+		if name == "" {
+			return nil, fmt.Errorf("%w: service_name must not be empty", ErrInvalidInput)
+		}
+		service, err := resolveCatalogService(ctx, s.catalog, name)
+		if err != nil {
+			return nil, err
+		}
+		if service != nil {
+			name = service.Name
+		}
+		budget.ServiceName = &name
+	default:
+		return nil, fmt.Errorf("%w: scope must be one of: user, category, service", ErrInvalidInput)
+	}
+
+	if err := s.repo.Create(ctx, budget); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"user_id": req.UserID,
+			"method":  "CreateBudget",
+		}).Error("Failed to create budget in repository")
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil, fmt.Errorf("%w: user or category does not exist", ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"budget_id": budget.ID.String(),
+		"method":    "CreateBudget",
+	}).Info("Budget created successfully")
+
+	return budget, nil
+}
+
+func (s *budgetService) GetBudget(ctx context.Context, id string) (result *models.Budget, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.GetBudget")
+	defer func() { tracing.End(span, err) }()
+
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid budget id", ErrInvalidInput)
+	}
+
+	budget, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetBudget",
+		}).Warn("Failed to get budget from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: budget %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) UpdateBudget(ctx context.Context, id string, req models.UpdateBudgetRequest) (result *models.Budget, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.UpdateBudget")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"budget_id":  id,
+		"has_period": req.Period != nil,
+		"has_amount": req.Amount != nil,
+		"method":     "UpdateBudget",
+	}).Info("Updating budget")
+
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid budget id", ErrInvalidInput)
+	}
+	if req.Period != nil {
+		if err := validateBudgetPeriod(*req.Period); err != nil {
+			return nil, err
+		}
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidInput)
+	}
+
+	if err := s.repo.Update(ctx, budgetID, &req); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "UpdateBudget",
+		}).Error("Failed to update budget in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: budget %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"budget_id": id,
+		"method":    "UpdateBudget",
+	}).Info("Budget updated successfully")
+
+	return s.GetBudget(ctx, id)
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.DeleteBudget")
+	defer func() { tracing.End(span, err) }()
+
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid budget id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, budgetID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "DeleteBudget",
+		}).Error("Failed to delete budget in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: budget %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"budget_id": id,
+		"method":    "DeleteBudget",
+	}).Info("Budget deleted successfully")
+
+	return nil
+}
+
+func (s *budgetService) ListBudgets(ctx context.Context, userID *string) (result []models.Budget, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.ListBudgets")
+	defer func() { tracing.End(span, err) }()
+
+	ownerID, err := parseOptionalUUID(userID, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.repo.List(ctx, ownerID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListBudgets",
+		}).Error("Failed to list budgets from repository")
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+func (s *budgetService) GetBudgetStatus(ctx context.Context, id string) (result *models.BudgetStatus, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.GetBudgetStatus")
+	defer func() { tracing.End(span, err) }()
+
+	budget, err := s.GetBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.evaluate(ctx, budget)
+}
+
+func (s *budgetService) ListBudgetStatuses(ctx context.Context, userID string) (result []models.BudgetStatus, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.ListBudgetStatuses")
+	defer func() { tracing.End(span, err) }()
+
+	budgets, err := s.ListBudgets(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.BudgetStatus, 0, len(budgets))
+	for i := range budgets {
+		status, err := s.evaluate(ctx, &budgets[i])
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	return statuses, nil
+}
+
+func (s *budgetService) CheckBudgets(ctx context.Context) (fired int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "BudgetService.CheckBudgets")
+	defer func() { tracing.End(span, err) }()
+
+	budgets, err := s.repo.List(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range budgets {
+		status, err := s.evaluate(ctx, &budgets[i])
+		if err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"error":     err.Error(),
+				"budget_id": budgets[i].ID.String(),
+				"method":    "CheckBudgets",
+			}).Error("Failed to evaluate budget")
+			continue
+		}
+
+		sent, err := s.alertThresholds(ctx, status)
+		if err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"error":     err.Error(),
+				"budget_id": budgets[i].ID.String(),
+				"method":    "CheckBudgets",
+			}).Error("Failed to send budget alert")
+			continue
+		}
+		if sent {
+			fired++
+		}
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"budgets": len(budgets),
+		"alerts":  fired,
+		"method":  "CheckBudgets",
+	}).Debug("Budgets checked")
+
+	return fired, nil
+}
+
+// alertThresholds записывает все впервые достигнутые за период пороги и
+// отправляет одно уведомление о наибольшем из них. Если отправка не удалась,
+// пороги не записываются и уведомление повторится при следующей проверке.
+func (s *budgetService) alertThresholds(ctx context.Context, status *models.BudgetStatus) (bool, error) {
+	var reached []models.BudgetAlert
+	for _, threshold := range budgetThresholds {
+		if status.SpentPercent < float64(threshold) {
+			break
+		}
+		reached = append(reached, models.BudgetAlert{
+			BudgetID:    status.Budget.ID,
+			PeriodStart: status.PeriodStart,
+			Threshold:   threshold,
+			Spent:       status.Spent,
+		})
+	}
+	if len(reached) == 0 {
+		return false, nil
+	}
+
+	recorded, err := s.repo.RecordAlerts(ctx, reached, func(recorded []models.BudgetAlert) error {
+		highest := recorded[len(recorded)-1].Threshold
+		return s.notifier.Notify(ctx, notify.Alert{
+			Type:   alertBudgetThreshold,
+			UserID: status.Budget.UserID,
+			Message: fmt.Sprintf("Budget %s reached %d%%: spent %d of %d %s",
+				budgetDescription(&status.Budget), highest, status.Spent, status.Budget.Amount, status.Budget.Currency),
+			Data: map[string]interface{}{
+				"budget_id":    status.Budget.ID,
+				"threshold":    highest,
+				"spent":        status.Spent,
+				"projected":    status.Projected,
+				"amount":       status.Budget.Amount,
+				"period_start": status.PeriodStart.Format(time.DateOnly),
+			},
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(recorded) > 0, nil
+}
+
+// evaluate считает расходы бюджета за текущий период той же логикой, что и сводка.
+func (s *budgetService) evaluate(ctx context.Context, budget *models.Budget) (*models.BudgetStatus, error) {
+	month := currentMonth()
+	periodStart, periodEnd := month, month
+	if budget.Period == models.BudgetPeriodYearly {
+		periodStart = time.Date(month.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd = time.Date(month.Year(), time.December, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	userID := budget.UserID.String()
+	req := models.SummaryRequest{
+		StartDate:   periodStart.Format("01-2006"),
+		EndDate:     month.Format("01-2006"),
+		UserID:      &userID,
+		ServiceName: budget.ServiceName,
+		CategoryID:  budget.CategoryID,
+	}
+	if budget.ServiceName != nil {
+		service, err := resolveCatalogService(ctx, s.catalog, *budget.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		if service != nil {
+			req.ServiceID = &service.ID
+		}
+	}
+
+	spent, err := s.subscriptions.GetSummary(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := spent
+	if periodEnd.After(month) {
+		req.EndDate = periodEnd.Format("01-2006")
+		if projected, err = s.subscriptions.GetSummary(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	status := &models.BudgetStatus{
+		Budget:           *budget,
+		PeriodStart:      periodStart,
+		PeriodEnd:        periodEnd,
+		Spent:            spent,
+		Projected:        projected,
+		SpentPercent:     budgetPercent(spent, budget.Amount),
+		ProjectedPercent: budgetPercent(projected, budget.Amount),
+		State:            models.BudgetStateOK,
+	}
+	switch {
+	case status.SpentPercent >= float64(budgetThresholds[len(budgetThresholds)-1]):
+		status.State = models.BudgetStateExceeded
+	case status.SpentPercent >= float64(budgetThresholds[0]):
+		status.State = models.BudgetStateWarning
+	}
+
+	return status, nil
+}
+
+func budgetPercent(value, amount int) float64 {
+	return math.Round(float64(value)/float64(amount)*10000) / 100
+}
+
+func budgetDescription(budget *models.Budget) string {
+	switch {
+	case budget.ServiceName != nil:
+		return fmt.Sprintf("%s for %s", budget.Period, *budget.ServiceName)
+	case budget.CategoryID != nil:
+		return fmt.Sprintf("%s for category %s", budget.Period, budget.CategoryID)
+	default:
+		return budget.Period
+	}
+}
+
+func validateBudgetPeriod(period string) error {
+	if period != models.BudgetPeriodMonthly && period != models.BudgetPeriodYearly {
+		return fmt.Errorf("%w: period must be one of: monthly, yearly", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"subscribe_project/internal/cache"
+	"subscribe_project/internal/repository"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// CacheInvalidator сбрасывает кэш подписок и сводок. Его вызывают после
+// успешной записи все сервисы, меняющие данные кэшированных ответов: паузы,
+// участники, фазы, теги, каталог, категории и способы оплаты.
+type CacheInvalidator interface {
+	// InvalidateSubscription сбрасывает подписку и сводки ее текущих
+	// владельца и участников, а также сводки users — например, только что
+	// удаленных участников.
+	InvalidateSubscription(ctx context.Context, id uuid.UUID, users ...uuid.UUID)
+	// InvalidateAll сбрасывает все подписки и сводки. Нужен для изменений,
+	// затрагивающих заранее неизвестный набор подписок: переименований
+	// сервисов, категорий и тегов, удаления способов оплаты.
+	InvalidateAll(ctx context.Context)
+}
+
+// NewNoopCacheInvalidator используется, когда кэш выключен.
+func NewNoopCacheInvalidator() CacheInvalidator {
+	return noopCacheInvalidator{}
+}
+
+type noopCacheInvalidator struct{}
+
+func (noopCacheInvalidator) InvalidateSubscription(context.Context, uuid.UUID, ...uuid.UUID) {}
+
+func (noopCacheInvalidator) InvalidateAll(context.Context) {}
+
+type cacheInvalidator struct {
+	cache   cache.Cache
+	members repository.MemberRepository
+}
+
+func NewCacheInvalidator(c cache.Cache, members repository.MemberRepository) CacheInvalidator {
+	return &cacheInvalidator{cache: c, members: members}
+}
+
+func (i *cacheInvalidator) InvalidateSubscription(ctx context.Context, id uuid.UUID, users ...uuid.UUID) {
+	tags := i.subscriptionTags(ctx, id.String())
+	for _, userID := range users {
+		tags = append(tags, cacheTagUserSummary+userID.String())
+	}
+	i.invalidate(ctx, tags)
+}
+
+func (i *cacheInvalidator) InvalidateAll(ctx context.Context) {
+	i.invalidate(ctx, []string{cacheTagAll})
+}
+
+// subscriptionTags собирает теги, которые затронет изменение подписки: саму
+// подписку и сводки владельца и участников. При удалении подписки или
+// участников теги нужно собрать до изменения, иначе сводки удаленных
+// пользователей не сбросятся.
+func (i *cacheInvalidator) subscriptionTags(ctx context.Context, id string) []string {
+	tags := []string{cacheTagSubscription + id, cacheTagAllSummaries}
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return tags
+	}
+	shares, err := i.members.ListShares(ctx, subscriptionID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": id,
+			"method":          "subscriptionTags",
+		}).Warn("Failed to list subscription users for cache invalidation")
+		return tags
+	}
+	for _, share := range shares {
+		tags = append(tags, cacheTagUserSummary+share.UserID.String())
+	}
+	return tags
+}
+
+func (i *cacheInvalidator) invalidate(ctx context.Context, tags []string) {
+	if err := i.cache.InvalidateTags(ctx, tags...); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+			"tags":  len(tags),
+		}).Error("Failed to invalidate cache")
+	}
+}
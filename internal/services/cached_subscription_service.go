@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"subscribe_project/internal/cache"
+	"subscribe_project/internal/metrics"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Теги записей кэша. Сводка без фильтра по пользователю помечается
+// cacheTagAllSummaries и сбрасывается при любом изменении подписок. Все
+// записи помечаются cacheTagAll для полного сброса.
+const (
+	cacheTagAll          = "all"
+	cacheTagAllSummaries = "summary:all"
+	cacheTagUserSummary  = "summary:user:"
+	cacheTagSubscription = "subscription:"
+)
+
+// cachedSubscriptionService кэширует GetSubscription и GetSummary и сбрасывает
+// записи владельца и участников подписки при ее изменении через этот сервис.
+// Остальные сервисы сбрасывают кэш через CacheInvalidator.
+type cachedSubscriptionService struct {
+	SubscriptionService
+	cache       cache.Cache
+	invalidator *cacheInvalidator
+	ttl         time.Duration
+}
+
+func NewCachedSubscriptionService(next SubscriptionService, c cache.Cache, members repository.MemberRepository, ttl time.Duration) SubscriptionService {
+	logger.Log.WithFields(logrus.Fields{
+		"component": "subscription_service",
+		"ttl":       ttl.String(),
+	}).Info("Enabling subscription read cache")
+	return &cachedSubscriptionService{
+		SubscriptionService: next,
+		cache:               c,
+		invalidator:         &cacheInvalidator{cache: c, members: members},
+		ttl:                 ttl,
+	}
+}
+
+func (s *cachedSubscriptionService) GetSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	id = normalizeCacheID(id)
+	return cachedRead(ctx, s, "subscription", cacheTagSubscription+id, []string{cacheTagSubscription + id},
+		func() (*models.Subscription, error) {
+			return s.SubscriptionService.GetSubscription(ctx, id)
+		})
+}
+
+func (s *cachedSubscriptionService) GetSummary(ctx context.Context, req models.SummaryRequest) (*models.SubscriptionSummary, error) {
+	key, tag := summaryCacheKey(req)
+	return cachedRead(ctx, s, "summary", key, []string{tag},
+		func() (*models.SubscriptionSummary, error) {
+			return s.SubscriptionService.GetSummary(ctx, req)
+		})
+}
+
+func (s *cachedSubscriptionService) CreateSubscription(ctx context.Context, req models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	subscription, err := s.SubscriptionService.CreateSubscription(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, []string{cacheTagAllSummaries, cacheTagUserSummary + subscription.UserID.String()})
+	return subscription, nil
+}
+
+func (s *cachedSubscriptionService) UpdateSubscription(ctx context.Context, id string, req models.UpdateSubscriptionRequest) error {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	if err := s.SubscriptionService.UpdateSubscription(ctx, id, req); err != nil {
+		return err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return nil
+}
+
+func (s *cachedSubscriptionService) DeleteSubscription(ctx context.Context, id string) error {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	if err := s.SubscriptionService.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return nil
+}
+
+func (s *cachedSubscriptionService) CancelSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	subscription, err := s.SubscriptionService.CancelSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return subscription, nil
+}
+
+func (s *cachedSubscriptionService) CancelAtPeriodEnd(ctx context.Context, id string, req models.CancelAtPeriodEndRequest) (*models.Subscription, error) {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	subscription, err := s.SubscriptionService.CancelAtPeriodEnd(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return subscription, nil
+}
+
+func (s *cachedSubscriptionService) ReactivateSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	subscription, err := s.SubscriptionService.ReactivateSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return subscription, nil
+}
+
+func (s *cachedSubscriptionService) MergeSubscriptions(ctx context.Context, req models.MergeSubscriptionsRequest) (*models.Subscription, error) {
+	tags := append(s.invalidator.subscriptionTags(ctx, normalizeCacheID(req.KeepID)), s.invalidator.subscriptionTags(ctx, normalizeCacheID(req.MergeID))...)
+	subscription, err := s.SubscriptionService.MergeSubscriptions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return subscription, nil
+}
+
+func (s *cachedSubscriptionService) RecordUsage(ctx context.Context, id string, req models.RecordUsageRequest) (*models.SubscriptionUsage, error) {
+	tags := s.invalidator.subscriptionTags(ctx, normalizeCacheID(id))
+	usage, err := s.SubscriptionService.RecordUsage(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidator.invalidate(ctx, tags)
+	return usage, nil
+}
+
+// cachedRead возвращает значение из кэша или загружает и сохраняет его.
+// Ошибки кэша не прерывают запрос: значение читается из базы.
+func cachedRead[T any](ctx context.Context, s *cachedSubscriptionService, name, key string, tags []string, load func() (*T, error)) (*T, error) {
+	data, ok, err := s.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		metrics.CacheRequestsTotal.WithLabelValues(name, "error").Inc()
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+			"cache": name,
+		}).Warn("Failed to read from cache")
+	case ok:
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			metrics.CacheRequestsTotal.WithLabelValues(name, "hit").Inc()
+			return &value, nil
+		}
+		metrics.CacheRequestsTotal.WithLabelValues(name, "error").Inc()
+	default:
+		metrics.CacheRequestsTotal.WithLabelValues(name, "miss").Inc()
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(value)
+	if err == nil {
+		err = s.cache.Set(ctx, key, data, s.ttl, append(tags, cacheTagAll)...)
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error": err.Error(),
+			"cache": name,
+		}).Warn("Failed to write to cache")
+	}
+	return value, nil
+}
+
+// normalizeCacheID приводит ID подписки к виду, в котором он входит в ключи и теги.
+func normalizeCacheID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// summaryCacheKey нормализует запрос сводки: одинаковые по смыслу запросы
+// получают один ключ.
+func summaryCacheKey(req models.SummaryRequest) (key, tag string) {
+	parts := []string{"summary", strings.TrimSpace(req.StartDate), strings.TrimSpace(req.EndDate)}
+
+	tag = cacheTagAllSummaries
+	if req.UserID != nil {
+		userID := strings.ToLower(strings.TrimSpace(*req.UserID))
+		parts = append(parts, "user="+userID)
+		tag = cacheTagUserSummary + userID
+	}
+	if req.ServiceName != nil {
+		// Без каталога сервис ищется по точному имени, поэтому регистр значим.
+		parts = append(parts, "service="+*req.ServiceName)
+	}
+	if req.GroupBy != nil {
+		parts = append(parts, "group="+*req.GroupBy)
+	}
+
+	return strings.Join(parts, "|"), tag
+}
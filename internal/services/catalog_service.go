@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type CatalogService interface {
+	CreateService(ctx context.Context, req models.CreateServiceRequest) (*models.Service, error)
+	GetService(ctx context.Context, id string) (*models.Service, error)
+	UpdateService(ctx context.Context, id string, req models.UpdateServiceRequest) error
+	DeleteService(ctx context.Context, id string) error
+	ListServices(ctx context.Context, page, limit int) ([]models.Service, error)
+	// ResolveService возвращает сервис каталога по имени или алиасу, либо nil.
+	ResolveService(ctx context.Context, name string) (*models.Service, error)
+}
+
+type catalogService struct {
+	repo        repository.CatalogRepository
+	invalidator CacheInvalidator
+}
+
+func NewCatalogService(repo repository.CatalogRepository, invalidator CacheInvalidator) CatalogService {
+	logger.Log.WithField("component", "catalog_service").Info("Creating new catalog service")
+	return &catalogService{repo: repo, invalidator: invalidator}
+}
+
+func (s *catalogService) CreateService(ctx context.Context, req models.CreateServiceRequest) (result *models.Service, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.CreateService")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"method":  "CreateService",
+		"name":    req.Name,
+		"aliases": len(req.Aliases),
+	}).Info("Creating catalog service")
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	aliases := normalizeAliases(name, req.Aliases)
+	if err := s.ensureAliasesFree(ctx, uuid.Nil, aliases); err != nil {
+		return nil, err
+	}
+
+	service := &models.Service{
+		Name:         name,
+		Aliases:      aliases,
+		Category:     req.Category,
+		Website:      req.Website,
+		DefaultPrice: req.DefaultPrice,
+		YearlyPrice:  req.YearlyPrice,
+		LogoURL:      req.LogoURL,
+	}
+
+	if err := s.repo.Create(ctx, service); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"name":   name,
+			"method": "CreateService",
+		}).Error("Failed to create catalog service in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: service %q already exists", ErrConflict, name)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"service_id": service.ID.String(),
+		"name":       service.Name,
+		"method":     "CreateService",
+	}).Info("Catalog service created successfully")
+
+	// Новый сервис привязывает существующие подписки с тем же именем.
+	s.invalidator.InvalidateAll(ctx)
+	return service, nil
+}
+
+func (s *catalogService) GetService(ctx context.Context, id string) (result *models.Service, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.GetService")
+	defer func() { tracing.End(span, err) }()
+
+	serviceID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid service id", ErrInvalidInput)
+	}
+
+	service, err := s.repo.GetByID(ctx, serviceID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "GetService",
+		}).Warn("Failed to get catalog service from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: service %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (s *catalogService) UpdateService(ctx context.Context, id string, req models.UpdateServiceRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.UpdateService")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"method": "UpdateService",
+		"id":     id,
+	}).Info("Updating catalog service")
+
+	current, err := s.GetService(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidInput)
+		}
+		req.Name = &name
+	}
+
+	if req.Name != nil || req.Aliases != nil {
+		name := current.Name
+		if req.Name != nil {
+			name = *req.Name
+		}
+		aliases := []string(current.Aliases)
+		if req.Aliases != nil {
+			aliases = *req.Aliases
+		}
+
+		normalized := normalizeAliases(name, aliases)
+		if err := s.ensureAliasesFree(ctx, current.ID, normalized); err != nil {
+			return err
+		}
+		req.Aliases = &normalized
+	}
+
+	if err := s.repo.Update(ctx, current.ID, &req); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "UpdateService",
+		}).Error("Failed to update catalog service in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return fmt.Errorf("%w: service name already taken", ErrConflict)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "UpdateService",
+	}).Info("Catalog service updated successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *catalogService) DeleteService(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.DeleteService")
+	defer func() { tracing.End(span, err) }()
+
+	serviceID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid service id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, serviceID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "DeleteService",
+		}).Error("Failed to delete catalog service from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: service %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "DeleteService",
+	}).Info("Catalog service deleted successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *catalogService) ListServices(ctx context.Context, page, limit int) (result []models.Service, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.ListServices")
+	defer func() { tracing.End(span, err) }()
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	services, err := s.repo.List(ctx, limit, (page-1)*limit)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListServices",
+		}).Error("Failed to list catalog services from repository")
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func (s *catalogService) ResolveService(ctx context.Context, name string) (result *models.Service, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CatalogService.ResolveService")
+	defer func() { tracing.End(span, err) }()
+
+	return resolveCatalogService(ctx, s.repo, name)
+}
+
+// ensureAliasesFree проверяет, что алиасы не принадлежат другому сервису,
+// иначе разрешение имен стало бы неоднозначным.
+func (s *catalogService) ensureAliasesFree(ctx context.Context, ownID uuid.UUID, aliases []string) error {
+	for _, alias := range aliases {
+		owner, err := resolveCatalogService(ctx, s.repo, alias)
+		if err != nil {
+			return err
+		}
+		if owner != nil && owner.ID != ownID {
+			return fmt.Errorf("%w: alias %q already belongs to service %q", ErrConflict, alias, owner.Name)
+		}
+	}
+	return nil
+}
+
+// resolveCatalogService ищет сервис каталога по имени; отсутствие записи не ошибка.
+func resolveCatalogService(ctx context.Context, repo repository.CatalogRepository, name string) (*models.Service, error) {
+	normalized := models.NormalizeServiceName(name)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	service, err := repo.Resolve(ctx, normalized)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).WithField("name", normalized).Debug("Service is not in catalog")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// normalizeAliases нормализует алиасы, убирает дубли и добавляет каноническое имя.
+func normalizeAliases(name string, aliases []string) []string {
+	seen := map[string]struct{}{}
+	result := make([]string, 0, len(aliases)+1)
+
+	for _, alias := range append([]string{name}, aliases...) {
+		normalized := models.NormalizeServiceName(alias)
+		if normalized == "" {
+			continue
+		}
+		if _, exists := seen[normalized]; exists {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		result = append(result, normalized)
+	}
+	return result
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, req models.CreateCategoryRequest) (*models.Category, error)
+	GetCategory(ctx context.Context, id string) (*models.Category, error)
+	UpdateCategory(ctx context.Context, id string, req models.UpdateCategoryRequest) error
+	DeleteCategory(ctx context.Context, id string) error
+	ListCategories(ctx context.Context) ([]models.Category, error)
+}
+
+type categoryService struct {
+	repo        repository.CategoryRepository
+	invalidator CacheInvalidator
+}
+
+func NewCategoryService(repo repository.CategoryRepository, invalidator CacheInvalidator) CategoryService {
+	logger.Log.WithField("component", "category_service").Info("Creating new category service")
+	return &categoryService{repo: repo, invalidator: invalidator}
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func (s *categoryService) CreateCategory(ctx context.Context, req models.CreateCategoryRequest) (result *models.Category, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CategoryService.CreateCategory")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"method": "CreateCategory",
+		"name":   req.Name,
+	}).Info("Creating category")
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	source := name
+	if req.Slug != nil {
+		source = *req.Slug
+	}
+	slug := makeSlug(source)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: slug must contain latin letters or digits", ErrInvalidInput)
+	}
+
+	category := &models.Category{Name: name, Slug: slug}
+
+	if req.ParentID != nil {
+		parent, err := s.GetCategory(ctx, *req.ParentID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: parent category %s does not exist", ErrInvalidInput, *req.ParentID)
+			}
+			return nil, err
+		}
+		category.ParentID = &parent.ID
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "CreateCategory",
+		}).Error("Failed to create category in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: category slug %q already taken", ErrConflict, slug)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"category_id": category.ID.String(),
+		"slug":        category.Slug,
+		"method":      "CreateCategory",
+	}).Info("Category created successfully")
+
+	return category, nil
+}
+
+func (s *categoryService) GetCategory(ctx context.Context, id string) (result *models.Category, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CategoryService.GetCategory")
+	defer func() { tracing.End(span, err) }()
+
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid category id", ErrInvalidInput)
+	}
+
+	category, err := s.repo.GetByID(ctx, categoryID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "GetCategory",
+		}).Warn("Failed to get category from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: category %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, id string, req models.UpdateCategoryRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CategoryService.UpdateCategory")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"method": "UpdateCategory",
+		"id":     id,
+	}).Info("Updating category")
+
+	current, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidInput)
+		}
+		req.Name = &name
+	}
+
+	if req.Slug != nil {
+		slug := makeSlug(*req.Slug)
+		if slug == "" {
+			return fmt.Errorf("%w: slug must contain latin letters or digits", ErrInvalidInput)
+		}
+		req.Slug = &slug
+	}
+
+	if req.ParentID != nil && *req.ParentID != "" {
+		parent, err := s.GetCategory(ctx, *req.ParentID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: parent category %s does not exist", ErrInvalidInput, *req.ParentID)
+			}
+			return err
+		}
+
+		// Родитель не может находиться в поддереве самой категории, иначе дерево зациклится.
+		cycle, err := s.repo.IsInSubtree(ctx, current.ID, parent.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: category cannot be moved under itself or its descendant", ErrInvalidInput)
+		}
+		req.ParentUUID = &parent.ID
+	}
+
+	if err := s.repo.Update(ctx, current.ID, &req); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "UpdateCategory",
+		}).Error("Failed to update category in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: category %s", ErrNotFound, id)
+		}
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return fmt.Errorf("%w: category slug already taken", ErrConflict)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "UpdateCategory",
+	}).Info("Category updated successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CategoryService.DeleteCategory")
+	defer func() { tracing.End(span, err) }()
+
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid category id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, categoryID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "DeleteCategory",
+		}).Error("Failed to delete category from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: category %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "DeleteCategory",
+	}).Info("Category deleted successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *categoryService) ListCategories(ctx context.Context) (result []models.Category, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CategoryService.ListCategories")
+	defer func() { tracing.End(span, err) }()
+
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListCategories",
+		}).Error("Failed to list categories from repository")
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// makeSlug приводит строку к виду "dev-tools": нижний регистр, латиница,
+// цифры и дефисы.
+func makeSlug(value string) string {
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultEventDays = 30
+	maxEventDays     = 365
+)
+
+type EventService interface {
+	ListUpcoming(ctx context.Context, req models.UpcomingEventsRequest) ([]models.UpcomingEvent, error)
+}
+
+type eventService struct {
+	repo repository.EventRepository
+}
+
+func NewEventService(repo repository.EventRepository) EventService {
+	logger.Log.WithField("component", "event_service").Info("Creating new event service")
+	return &eventService{repo: repo}
+}
+
+func (s *eventService) ListUpcoming(ctx context.Context, req models.UpcomingEventsRequest) (result []models.UpcomingEvent, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "EventService.ListUpcoming")
+	defer func() { tracing.End(span, err) }()
+
+	days := req.Days
+	if days <= 0 {
+		days = defaultEventDays
+	}
+	if days > maxEventDays {
+		return nil, fmt.Errorf("%w: days must not exceed %d", ErrInvalidInput, maxEventDays)
+	}
+
+	var userID *uuid.UUID
+	if req.UserID != nil {
+		id, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid user_id", ErrInvalidInput)
+		}
+		userID = &id
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := today.AddDate(0, 0, days)
+
+	events, err := s.repo.Upcoming(ctx, today, to, userID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListUpcoming",
+		}).Error("Failed to list upcoming events from repository")
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"days":   days,
+		"count":  len(events),
+		"method": "ListUpcoming",
+	}).Debug("Upcoming events listed")
+
+	return events, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultForecastMonths = 12
+	maxForecastMonths     = 36
+)
+
+type ForecastService interface {
+	// Forecast прогнозирует расходы на ближайшие месяцы, начиная с текущего.
+	// Обычная цена подписки считается неизменной: из будущих изменений цены
+	// известны только переходы между фазами.
+	Forecast(ctx context.Context, req models.ForecastRequest) (*models.Forecast, error)
+}
+
+type forecastService struct {
+	repo    repository.ForecastRepository
+	catalog repository.CatalogRepository
+}
+
+func NewForecastService(repo repository.ForecastRepository, catalog repository.CatalogRepository) ForecastService {
+	logger.Log.WithField("component", "forecast_service").Info("Creating new forecast service")
+	return &forecastService{repo: repo, catalog: catalog}
+}
+
+func (s *forecastService) Forecast(ctx context.Context, req models.ForecastRequest) (result *models.Forecast, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ForecastService.Forecast")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"months":           req.Months,
+		"has_user_id":      req.UserID != nil,
+		"has_service_name": req.ServiceName != nil,
+		"method":           "Forecast",
+	}).Info("Forecasting subscription costs")
+
+	months := req.Months
+	if months == 0 {
+		months = defaultForecastMonths
+	}
+	if months < 1 || months > maxForecastMonths {
+		return nil, fmt.Errorf("%w: months must be between 1 and %d", ErrInvalidInput, maxForecastMonths)
+	}
+
+	if _, err := parseOptionalUUID(req.UserID, "user_id"); err != nil {
+		return nil, err
+	}
+
+	from := currentMonth()
+	to := from.AddDate(0, months-1, 0)
+
+	summaryReq := models.SummaryRequest{
+		StartDate:   from.Format("01-2006"),
+		EndDate:     to.Format("01-2006"),
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+	}
+	if req.ServiceName != nil {
+		service, err := resolveCatalogService(ctx, s.catalog, *req.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		if service != nil {
+			summaryReq.ServiceID = &service.ID
+		}
+	}
+
+	rows, err := s.repo.MonthlyCosts(ctx, summaryReq)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "Forecast",
+		}).Error("Failed to get monthly costs from repository")
+		return nil, err
+	}
+
+	forecast := &models.Forecast{
+		From:   from,
+		To:     to,
+		Months: make([]models.ForecastMonth, months),
+	}
+	for i := range forecast.Months {
+		forecast.Months[i] = models.ForecastMonth{
+			Month:    from.AddDate(0, i, 0),
+			Services: []models.ForecastServiceCost{},
+		}
+	}
+	for _, row := range rows {
+		i := (row.Month.Year()-from.Year())*12 + int(row.Month.Month()) - int(from.Month())
+		if i < 0 || i >= months {
+			continue
+		}
+		month := &forecast.Months[i]
+		month.TotalCost += row.Cost
+		month.Services = append(month.Services, models.ForecastServiceCost{
+			ServiceName: row.ServiceName,
+			Cost:        row.Cost,
+		})
+		forecast.TotalCost += row.Cost
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"months":     months,
+		"total_cost": forecast.TotalCost,
+		"method":     "Forecast",
+	}).Info("Forecast calculated successfully")
+
+	return forecast, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type MemberService interface {
+	GetMembers(ctx context.Context, subscriptionID string) (*models.SubscriptionMembers, error)
+	// SetMembers задает правило разделения и заменяет список участников.
+	SetMembers(ctx context.Context, subscriptionID string, req models.SetMembersRequest) (*models.SubscriptionMembers, error)
+	RemoveMember(ctx context.Context, subscriptionID, userID string) error
+}
+
+type memberService struct {
+	repo          repository.MemberRepository
+	subscriptions repository.SubscriptionRepository
+	users         repository.UserRepository
+	invalidator   CacheInvalidator
+}
+
+func NewMemberService(repo repository.MemberRepository, subscriptions repository.SubscriptionRepository, users repository.UserRepository, invalidator CacheInvalidator) MemberService {
+	logger.Log.WithField("component", "member_service").Info("Creating new member service")
+	return &memberService{repo: repo, subscriptions: subscriptions, users: users, invalidator: invalidator}
+}
+
+func (s *memberService) GetMembers(ctx context.Context, subscriptionID string) (result *models.SubscriptionMembers, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "MemberService.GetMembers")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.repo.ListShares(ctx, subscription.ID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "GetMembers",
+		}).Error("Failed to list subscription shares from repository")
+		return nil, err
+	}
+
+	return &models.SubscriptionMembers{
+		SubscriptionID: subscription.ID,
+		SplitRule:      subscription.SplitRule,
+		Shares:         shares,
+	}, nil
+}
+
+func (s *memberService) SetMembers(ctx context.Context, subscriptionID string, req models.SetMembersRequest) (result *models.SubscriptionMembers, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "MemberService.SetMembers")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"split_rule":      req.SplitRule,
+		"members":         len(req.Members),
+		"method":          "SetMembers",
+	}).Info("Setting subscription members")
+
+	subscription, err := s.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.buildMembers(ctx, subscription, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Прежние участники читаются до замены: их сводки тоже нужно сбросить.
+	previous, err := s.repo.ListShares(ctx, subscription.ID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "SetMembers",
+		}).Error("Failed to list current subscription shares from repository")
+		return nil, err
+	}
+	previousUsers := make([]uuid.UUID, 0, len(previous))
+	for _, share := range previous {
+		previousUsers = append(previousUsers, share.UserID)
+	}
+
+	if err := s.repo.Replace(ctx, subscription.ID, req.SplitRule, members); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "SetMembers",
+		}).Error("Failed to replace subscription members in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, subscriptionID)
+		}
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil, fmt.Errorf("%w: member user does not exist", ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"split_rule":      req.SplitRule,
+		"members":         len(members),
+		"method":          "SetMembers",
+	}).Info("Subscription members updated successfully")
+
+	s.invalidator.InvalidateSubscription(ctx, subscription.ID, previousUsers...)
+	return s.GetMembers(ctx, subscriptionID)
+}
+
+func (s *memberService) RemoveMember(ctx context.Context, subscriptionID, userID string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "MemberService.RemoveMember")
+	defer func() { tracing.End(span, err) }()
+
+	subID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+	memberID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Remove(ctx, subID, memberID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "RemoveMember",
+		}).Error("Failed to remove subscription member in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user is not a member of subscription %s", ErrNotFound, subscriptionID)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"method":          "RemoveMember",
+	}).Info("Subscription member removed successfully")
+
+	s.invalidator.InvalidateSubscription(ctx, subID, memberID)
+	return nil
+}
+
+func (s *memberService) getSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	subscription, err := s.subscriptions.GetByID(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// buildMembers проверяет участников и их доли по правилу разделения.
+// Доли участников не могут превышать стоимость подписки: остаток платит владелец.
+func (s *memberService) buildMembers(ctx context.Context, subscription *models.Subscription, req models.SetMembersRequest) ([]models.SubscriptionMember, error) {
+	switch req.SplitRule {
+	case models.SplitRuleEqual, models.SplitRulePercentage, models.SplitRuleFixed:
+	default:
+		return nil, fmt.Errorf("%w: split_rule must be one of: equal, percentage, fixed", ErrInvalidInput)
+	}
+
+	members := make([]models.SubscriptionMember, 0, len(req.Members))
+	seen := map[uuid.UUID]struct{}{}
+	var percentTotal float64
+	var amountTotal int
+
+	for _, member := range req.Members {
+		userID, err := uuid.Parse(member.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid member user_id %q", ErrInvalidInput, member.UserID)
+		}
+		if userID == subscription.UserID {
+			return nil, fmt.Errorf("%w: subscription owner cannot be a member", ErrInvalidInput)
+		}
+		if _, duplicate := seen[userID]; duplicate {
+			return nil, fmt.Errorf("%w: duplicate member %s", ErrInvalidInput, userID)
+		}
+		seen[userID] = struct{}{}
+
+		if _, err := s.users.GetByID(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalidInput, userID)
+			}
+			return nil, err
+		}
+
+		entry := models.SubscriptionMember{UserID: userID}
+		switch req.SplitRule {
+		case models.SplitRulePercentage:
+			if member.SharePercent == nil || *member.SharePercent <= 0 || *member.SharePercent > 100 {
+				return nil, fmt.Errorf("%w: share_percent in (0, 100] is required for member %s", ErrInvalidInput, userID)
+			}
+			percentTotal += *member.SharePercent
+			entry.SharePercent = member.SharePercent
+		case models.SplitRuleFixed:
+			if member.ShareAmount == nil || *member.ShareAmount <= 0 {
+				return nil, fmt.Errorf("%w: positive share_amount is required for member %s", ErrInvalidInput, userID)
+			}
+			amountTotal += *member.ShareAmount
+			entry.ShareAmount = member.ShareAmount
+		}
+		members = append(members, entry)
+	}
+
+	if percentTotal > 100 {
+		return nil, fmt.Errorf("%w: member shares exceed 100%%", ErrInvalidInput)
+	}
+	if amountTotal > subscription.Price {
+		return nil, fmt.Errorf("%w: member shares exceed subscription price %d", ErrInvalidInput, subscription.Price)
+	}
+
+	return members, nil
+}
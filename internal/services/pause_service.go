@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type PauseService interface {
+	// PauseSubscription приостанавливает оплату с указанного месяца.
+	PauseSubscription(ctx context.Context, id string, req models.PauseSubscriptionRequest) (*models.Subscription, error)
+	// ResumeSubscription возобновляет оплату с указанного месяца.
+	ResumeSubscription(ctx context.Context, id string, req models.ResumeSubscriptionRequest) (*models.Subscription, error)
+	ListPauses(ctx context.Context, id string) ([]models.SubscriptionPause, error)
+}
+
+type pauseService struct {
+	repo          repository.PauseRepository
+	subscriptions repository.SubscriptionRepository
+	invalidator   CacheInvalidator
+}
+
+func NewPauseService(repo repository.PauseRepository, subscriptions repository.SubscriptionRepository, invalidator CacheInvalidator) PauseService {
+	logger.Log.WithField("component", "pause_service").Info("Creating new pause service")
+	return &pauseService{repo: repo, subscriptions: subscriptions, invalidator: invalidator}
+}
+
+func (s *pauseService) PauseSubscription(ctx context.Context, id string, req models.PauseSubscriptionRequest) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PauseService.PauseSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":         id,
+		"has_start":  req.StartDate != nil,
+		"has_resume": req.ResumeDate != nil,
+		"method":     "PauseSubscription",
+	}).Info("Pausing subscription")
+
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTransition(subscription.Status, models.StatusPaused); err != nil {
+		return nil, err
+	}
+
+	start, err := parseMonthOrCurrent(req.StartDate, "start_date")
+	if err != nil {
+		return nil, err
+	}
+	if start.Before(subscription.StartDate) {
+		return nil, fmt.Errorf("%w: pause cannot start before the subscription", ErrInvalidInput)
+	}
+	if subscription.EndDate != nil && start.After(*subscription.EndDate) {
+		return nil, fmt.Errorf("%w: pause cannot start after the subscription ends", ErrInvalidInput)
+	}
+
+	pause := &models.SubscriptionPause{SubscriptionID: subscription.ID, StartDate: start}
+	if req.ResumeDate != nil {
+		resume, err := parseMonthOrCurrent(req.ResumeDate, "resume_date")
+		if err != nil {
+			return nil, err
+		}
+		if !resume.After(start) {
+			return nil, fmt.Errorf("%w: resume_date must be after start_date", ErrInvalidInput)
+		}
+		pause.EndDate = &resume
+	}
+
+	if err := s.repo.Create(ctx, pause); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "PauseSubscription",
+		}).Error("Failed to create subscription pause in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: subscription is already paused in this period", ErrConflict)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":       id,
+		"pause_id": pause.ID.String(),
+		"method":   "PauseSubscription",
+	}).Info("Subscription paused successfully")
+
+	s.invalidator.InvalidateSubscription(ctx, subscription.ID)
+	return s.getSubscription(ctx, id)
+}
+
+func (s *pauseService) ResumeSubscription(ctx context.Context, id string, req models.ResumeSubscriptionRequest) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PauseService.ResumeSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":         id,
+		"has_resume": req.ResumeDate != nil,
+		"method":     "ResumeSubscription",
+	}).Info("Resuming subscription")
+
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resume, err := parseMonthOrCurrent(req.ResumeDate, "resume_date")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Resume(ctx, subscription.ID, resume); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "ResumeSubscription",
+		}).Error("Failed to resume subscription in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription is not paused", ErrConflict)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "ResumeSubscription",
+	}).Info("Subscription resumed successfully")
+
+	s.invalidator.InvalidateSubscription(ctx, subscription.ID)
+	return s.getSubscription(ctx, id)
+}
+
+func (s *pauseService) ListPauses(ctx context.Context, id string) (result []models.SubscriptionPause, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PauseService.ListPauses")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pauses, err := s.repo.List(ctx, subscription.ID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "ListPauses",
+		}).Error("Failed to list subscription pauses from repository")
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+func (s *pauseService) getSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	subscription, err := s.subscriptions.GetByID(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// parseMonthOrCurrent разбирает месяц в формате MM-YYYY; без значения
+// возвращает текущий месяц.
+func parseMonthOrCurrent(value *string, field string) (time.Time, error) {
+	if value == nil {
+		return currentMonth(), nil
+	}
+
+	month, err := time.Parse("01-2006", *value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid %s format, expected MM-YYYY", ErrInvalidInput, field)
+	}
+	return month, nil
+}
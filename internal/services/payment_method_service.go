@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// expiringHorizonMonths — на сколько месяцев вперед ищется ближайшее списание.
+const expiringHorizonMonths = 12
+
+type PaymentMethodService interface {
+	CreatePaymentMethod(ctx context.Context, userID string, req models.CreatePaymentMethodRequest) (*models.PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, id string) (*models.PaymentMethod, error)
+	// UpdatePaymentMethod меняет название или срок действия, например после перевыпуска карты.
+	UpdatePaymentMethod(ctx context.Context, id string, req models.UpdatePaymentMethodRequest) (*models.PaymentMethod, error)
+	// DeletePaymentMethod удаляет способ оплаты и отвязывает его от подписок.
+	DeletePaymentMethod(ctx context.Context, id string) error
+	ListUserPaymentMethods(ctx context.Context, userID string) ([]models.PaymentMethod, error)
+	// ListExpiring возвращает подписки, способ оплаты которых истечет до ближайшего списания.
+	ListExpiring(ctx context.Context, userID *string) ([]models.ExpiringPaymentMethod, error)
+}
+
+type paymentMethodService struct {
+	repo        repository.PaymentMethodRepository
+	users       repository.UserRepository
+	invalidator CacheInvalidator
+}
+
+func NewPaymentMethodService(repo repository.PaymentMethodRepository, users repository.UserRepository, invalidator CacheInvalidator) PaymentMethodService {
+	logger.Log.WithField("component", "payment_method_service").Info("Creating new payment method service")
+	return &paymentMethodService{repo: repo, users: users, invalidator: invalidator}
+}
+
+func (s *paymentMethodService) CreatePaymentMethod(ctx context.Context, userID string, req models.CreatePaymentMethodRequest) (result *models.PaymentMethod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.CreatePaymentMethod")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+		"type":    req.Type,
+		"method":  "CreatePaymentMethod",
+	}).Info("Creating payment method")
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	if _, err := s.users.GetByID(ctx, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+
+	switch req.Type {
+	case models.PaymentMethodCard, models.PaymentMethodBankAccount, models.PaymentMethodWallet:
+	default:
+		return nil, fmt.Errorf("%w: type must be one of: card, bank_account, wallet", ErrInvalidInput)
+	}
+
+	label, err := normalizeMaskedLabel(req.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	method := &models.PaymentMethod{UserID: ownerID, Type: req.Type, Label: label}
+	if req.ExpiryMonth != nil && *req.ExpiryMonth != "" {
+		expiry, err := time.Parse("01-2006", *req.ExpiryMonth)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid expiry_month format, expected MM-YYYY", ErrInvalidInput)
+		}
+		method.ExpiryMonth = &expiry
+	}
+
+	if err := s.repo.Create(ctx, method); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+			"method":  "CreatePaymentMethod",
+		}).Error("Failed to create payment method in repository")
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_method_id": method.ID.String(),
+		"user_id":           userID,
+		"method":            "CreatePaymentMethod",
+	}).Info("Payment method created successfully")
+
+	return method, nil
+}
+
+func (s *paymentMethodService) GetPaymentMethod(ctx context.Context, id string) (result *models.PaymentMethod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.GetPaymentMethod")
+	defer func() { tracing.End(span, err) }()
+
+	methodID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid payment method id", ErrInvalidInput)
+	}
+
+	method, err := s.repo.GetByID(ctx, methodID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetPaymentMethod",
+		}).Warn("Failed to get payment method from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: payment method %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (s *paymentMethodService) UpdatePaymentMethod(ctx context.Context, id string, req models.UpdatePaymentMethodRequest) (result *models.PaymentMethod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.UpdatePaymentMethod")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_method_id": id,
+		"has_label":         req.Label != nil,
+		"has_expiry":        req.ExpiryMonth != nil,
+		"method":            "UpdatePaymentMethod",
+	}).Info("Updating payment method")
+
+	methodID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid payment method id", ErrInvalidInput)
+	}
+
+	if req.Label != nil {
+		label, err := normalizeMaskedLabel(*req.Label)
+		if err != nil {
+			return nil, err
+		}
+		req.Label = &label
+	}
+
+	if req.ExpiryMonth != nil && *req.ExpiryMonth != "" {
+		expiry, err := time.Parse("01-2006", *req.ExpiryMonth)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid expiry_month format, expected MM-YYYY", ErrInvalidInput)
+		}
+		req.ExpiryDate = &expiry
+	}
+
+	if err := s.repo.Update(ctx, methodID, &req); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "UpdatePaymentMethod",
+		}).Error("Failed to update payment method in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: payment method %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_method_id": id,
+		"method":            "UpdatePaymentMethod",
+	}).Info("Payment method updated successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return s.GetPaymentMethod(ctx, id)
+}
+
+func (s *paymentMethodService) DeletePaymentMethod(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.DeletePaymentMethod")
+	defer func() { tracing.End(span, err) }()
+
+	methodID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid payment method id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, methodID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "DeletePaymentMethod",
+		}).Error("Failed to delete payment method in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: payment method %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_method_id": id,
+		"method":            "DeletePaymentMethod",
+	}).Info("Payment method deleted successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *paymentMethodService) ListUserPaymentMethods(ctx context.Context, userID string) (result []models.PaymentMethod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.ListUserPaymentMethods")
+	defer func() { tracing.End(span, err) }()
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	if _, err := s.users.GetByID(ctx, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+
+	methods, err := s.repo.ListByUser(ctx, ownerID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListUserPaymentMethods",
+		}).Error("Failed to list payment methods from repository")
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (s *paymentMethodService) ListExpiring(ctx context.Context, userID *string) (result []models.ExpiringPaymentMethod, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentMethodService.ListExpiring")
+	defer func() { tracing.End(span, err) }()
+
+	ownerID, err := parseOptionalUUID(userID, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := currentMonth().AddDate(0, expiringHorizonMonths, 0)
+
+	expiring, err := s.repo.Expiring(ctx, today, until, ownerID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListExpiring",
+		}).Error("Failed to list expiring payment methods from repository")
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"count":  len(expiring),
+		"method": "ListExpiring",
+	}).Debug("Expiring payment methods listed")
+
+	return expiring, nil
+}
+
+// maxLabelDigits — максимум цифр в названии способа оплаты. Маскированный
+// номер (первые 6 и последние 4 цифры) проходит, полный номер карты или счета — нет.
+const maxLabelDigits = 10
+
+// normalizeMaskedLabel не допускает полных номеров карт и счетов в названии.
+func normalizeMaskedLabel(label string) (string, error) {
+	label = strings.Join(strings.Fields(label), " ")
+	if label == "" {
+		return "", fmt.Errorf("%w: label is required", ErrInvalidInput)
+	}
+	if len([]rune(label)) > 100 {
+		return "", fmt.Errorf("%w: label must not exceed 100 characters", ErrInvalidInput)
+	}
+
+	digits := 0
+	for _, r := range label {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits > maxLabelDigits {
+		return "", fmt.Errorf("%w: label must be masked, e.g. \"Visa •••• 4242\"", ErrInvalidInput)
+	}
+	return label, nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentService interface {
+	CreatePayment(ctx context.Context, req models.CreatePaymentRequest) (*models.Payment, error)
+	GetPayment(ctx context.Context, id string) (*models.Payment, error)
+	DeletePayment(ctx context.Context, id string) error
+	ListPayments(ctx context.Context, req models.ListPaymentsRequest) ([]models.Payment, error)
+	// Reconcile строит отчет сверки ожидаемых списаний с платежами за период.
+	Reconcile(ctx context.Context, req models.ReconciliationRequest) (*models.ReconciliationReport, error)
+}
+
+type paymentService struct {
+	repo repository.PaymentRepository
+}
+
+func NewPaymentService(repo repository.PaymentRepository) PaymentService {
+	logger.Log.WithField("component", "payment_service").Info("Creating new payment service")
+	return &paymentService{repo: repo}
+}
+
+func (s *paymentService) CreatePayment(ctx context.Context, req models.CreatePaymentRequest) (result *models.Payment, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentService.CreatePayment")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": req.SubscriptionID,
+		"amount":          req.Amount,
+		"method":          "CreatePayment",
+	}).Info("Recording payment")
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription_id", ErrInvalidInput)
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidInput)
+	}
+
+	paidAt, err := time.Parse(time.DateOnly, req.PaidAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid paid_at format, expected YYYY-MM-DD", ErrInvalidInput)
+	}
+
+	payment := &models.Payment{
+		SubscriptionID: subscriptionID,
+		Amount:         req.Amount,
+		Currency:       models.DefaultCurrency,
+		PaidAt:         paidAt,
+		PaymentMethod:  trimOptional(req.PaymentMethod),
+		Reference:      trimOptional(req.Reference),
+	}
+
+	if req.Currency != nil && *req.Currency != "" {
+		currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidInput)
+		}
+		payment.Currency = currency
+	}
+
+	if err := s.repo.Create(ctx, payment); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": req.SubscriptionID,
+			"method":          "CreatePayment",
+		}).Error("Failed to create payment in repository")
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, req.SubscriptionID)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_id":      payment.ID.String(),
+		"subscription_id": req.SubscriptionID,
+		"method":          "CreatePayment",
+	}).Info("Payment recorded successfully")
+
+	return payment, nil
+}
+
+func (s *paymentService) GetPayment(ctx context.Context, id string) (result *models.Payment, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentService.GetPayment")
+	defer func() { tracing.End(span, err) }()
+
+	paymentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid payment id", ErrInvalidInput)
+	}
+
+	payment, err := s.repo.GetByID(ctx, paymentID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetPayment",
+		}).Warn("Failed to get payment from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: payment %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (s *paymentService) DeletePayment(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentService.DeletePayment")
+	defer func() { tracing.End(span, err) }()
+
+	paymentID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid payment id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, paymentID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "DeletePayment",
+		}).Error("Failed to delete payment in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: payment %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"payment_id": id,
+		"method":     "DeletePayment",
+	}).Info("Payment deleted successfully")
+
+	return nil
+}
+
+func (s *paymentService) ListPayments(ctx context.Context, req models.ListPaymentsRequest) (result []models.Payment, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentService.ListPayments")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	filter := models.PaymentFilter{Limit: req.Limit, Offset: (req.Page - 1) * req.Limit}
+	if filter.SubscriptionID, err = parseOptionalUUID(req.SubscriptionID, "subscription_id"); err != nil {
+		return nil, err
+	}
+	if filter.UserID, err = parseOptionalUUID(req.UserID, "user_id"); err != nil {
+		return nil, err
+	}
+	if filter.From, err = parseOptionalDate(req.From, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseOptionalDate(req.To, "to"); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.repo.List(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListPayments",
+		}).Error("Failed to list payments from repository")
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (s *paymentService) Reconcile(ctx context.Context, req models.ReconciliationRequest) (result *models.ReconciliationReport, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PaymentService.Reconcile")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"start_date": req.StartDate,
+		"end_date":   req.EndDate,
+		"method":     "Reconcile",
+	}).Info("Reconciling payments")
+
+	from, err := time.Parse("01-2006", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_date format, expected MM-YYYY", ErrInvalidInput)
+	}
+	to, err := time.Parse("01-2006", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end_date format, expected MM-YYYY", ErrInvalidInput)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidInput)
+	}
+
+	filter := models.ReconciliationFilter{From: from, To: to}
+	if filter.UserID, err = parseOptionalUUID(req.UserID, "user_id"); err != nil {
+		return nil, err
+	}
+	if filter.SubscriptionID, err = parseOptionalUUID(req.SubscriptionID, "subscription_id"); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.Reconcile(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "Reconcile",
+		}).Error("Failed to reconcile payments in repository")
+		return nil, err
+	}
+
+	report := &models.ReconciliationReport{Items: make([]models.ReconciliationItem, 0, len(items))}
+	for _, item := range items {
+		item.Status = reconciliationStatus(item)
+		if item.Status != models.ReconciliationOK {
+			report.Issues++
+		}
+		report.TotalExpected += item.ExpectedAmount
+		report.TotalPaid += item.PaidAmount
+		report.Items = append(report.Items, item)
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"items":  len(report.Items),
+		"issues": report.Issues,
+		"method": "Reconcile",
+	}).Info("Payments reconciled successfully")
+
+	return report, nil
+}
+
+// reconciliationStatus классифицирует месяц подписки. Несколько платежей,
+// в сумме равных ожидаемому списанию, считаются оплатой частями.
+func reconciliationStatus(item models.ReconciliationItem) string {
+	switch {
+	case item.Payments == 0:
+		return models.ReconciliationMissing
+	case item.ExpectedAmount == 0:
+		return models.ReconciliationUnexpected
+	case item.ForeignPayments > 0:
+		return models.ReconciliationMismatch
+	case item.PaidAmount == item.ExpectedAmount:
+		return models.ReconciliationOK
+	case item.Payments > 1 && item.PaidAmount > item.ExpectedAmount:
+		return models.ReconciliationDuplicate
+	default:
+		return models.ReconciliationMismatch
+	}
+}
+
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func parseOptionalUUID(value *string, field string) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidInput, field)
+	}
+	return &id, nil
+}
+
+func parseOptionalDate(value *string, field string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s format, expected YYYY-MM-DD", ErrInvalidInput, field)
+	}
+	return &date, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxPhaseMonths ограничивает суммарную длительность начальных фаз.
+const maxPhaseMonths = 36
+
+type PhaseService interface {
+	// GetPhases возвращает полное расписание цен подписки, включая фазу regular.
+	GetPhases(ctx context.Context, subscriptionID string) ([]models.SubscriptionPhase, error)
+	SetPhases(ctx context.Context, subscriptionID string, req models.SetPhasesRequest) ([]models.SubscriptionPhase, error)
+}
+
+type phaseService struct {
+	repo          repository.PhaseRepository
+	subscriptions repository.SubscriptionRepository
+	invalidator   CacheInvalidator
+}
+
+func NewPhaseService(repo repository.PhaseRepository, subscriptions repository.SubscriptionRepository, invalidator CacheInvalidator) PhaseService {
+	logger.Log.WithField("component", "phase_service").Info("Creating new phase service")
+	return &phaseService{repo: repo, subscriptions: subscriptions, invalidator: invalidator}
+}
+
+func (s *phaseService) GetPhases(ctx context.Context, subscriptionID string) (result []models.SubscriptionPhase, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PhaseService.GetPhases")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	subscription, err := s.subscriptions.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, subscriptionID)
+		}
+		return nil, err
+	}
+
+	phases, err := s.repo.List(ctx, id)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "GetPhases",
+		}).Error("Failed to list subscription phases from repository")
+		return nil, err
+	}
+
+	regular := models.SubscriptionPhase{
+		Sequence:  len(phases) + 1,
+		PhaseType: models.PhaseRegular,
+		Price:     subscription.Price,
+		StartDate: subscription.StartDate,
+	}
+	if len(phases) > 0 && phases[len(phases)-1].EndDate != nil {
+		regular.StartDate = *phases[len(phases)-1].EndDate
+	}
+
+	return append(phases, regular), nil
+}
+
+func (s *phaseService) SetPhases(ctx context.Context, subscriptionID string, req models.SetPhasesRequest) (result []models.SubscriptionPhase, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PhaseService.SetPhases")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"phases":          len(req.Phases),
+		"method":          "SetPhases",
+	}).Info("Setting subscription phases")
+
+	id, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	phases, err := buildPhases(req.Phases)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Replace(ctx, id, phases); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "SetPhases",
+		}).Error("Failed to replace subscription phases in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, subscriptionID)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"phases":          len(phases),
+		"method":          "SetPhases",
+	}).Info("Subscription phases updated successfully")
+
+	s.invalidator.InvalidateSubscription(ctx, id)
+	return s.GetPhases(ctx, subscriptionID)
+}
+
+// buildPhases проверяет начальные фазы: пробные периоды бесплатны и идут
+// перед фазами со вводной ценой.
+func buildPhases(requests []models.PhaseRequest) ([]models.SubscriptionPhase, error) {
+	phases := make([]models.SubscriptionPhase, 0, len(requests))
+	totalMonths := 0
+	seenIntro := false
+
+	for i, req := range requests {
+		if req.DurationMonths <= 0 {
+			return nil, fmt.Errorf("%w: phase %d: duration_months must be positive", ErrInvalidInput, i+1)
+		}
+
+		switch req.PhaseType {
+		case models.PhaseTrial:
+			if seenIntro {
+				return nil, fmt.Errorf("%w: phase %d: trial must precede intro phases", ErrInvalidInput, i+1)
+			}
+			if req.Price != 0 {
+				return nil, fmt.Errorf("%w: phase %d: trial must be free, use intro for discounted months", ErrInvalidInput, i+1)
+			}
+		case models.PhaseIntro:
+			seenIntro = true
+			if req.Price < 0 {
+				return nil, fmt.Errorf("%w: phase %d: price must not be negative", ErrInvalidInput, i+1)
+			}
+		default:
+			return nil, fmt.Errorf("%w: phase %d: phase_type must be one of: trial, intro", ErrInvalidInput, i+1)
+		}
+
+		totalMonths += req.DurationMonths
+		duration := req.DurationMonths
+		phases = append(phases, models.SubscriptionPhase{
+			PhaseType:      req.PhaseType,
+			DurationMonths: &duration,
+			Price:          req.Price,
+		})
+	}
+
+	if totalMonths > maxPhaseMonths {
+		return nil, fmt.Errorf("%w: initial phases must not exceed %d months", ErrInvalidInput, maxPhaseMonths)
+	}
+
+	return phases, nil
+}
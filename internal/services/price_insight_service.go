@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Правила поиска аномалий цен, кроме порога роста, который задается в конфигурации.
+const (
+	priceIncreaseWindowDays = 90
+	priceOutlierMinSample   = 5
+	priceIncreaseMinUsers   = 3
+)
+
+type PriceInsightService interface {
+	// Refresh пересчитывает распределения цен по сервисам и аномалии:
+	// выбросы среди действующих подписок и повышения цен за последние 90 дней.
+	Refresh(ctx context.Context) (int, error)
+	GetInsights(ctx context.Context, req models.PriceInsightsRequest) (*models.PriceInsights, error)
+}
+
+type priceInsightService struct {
+	repo              repository.PriceInsightRepository
+	increaseThreshold float64
+}
+
+func NewPriceInsightService(repo repository.PriceInsightRepository, increaseThreshold float64) PriceInsightService {
+	logger.Log.WithField("component", "price_insight_service").Info("Creating new price insight service")
+	return &priceInsightService{repo: repo, increaseThreshold: increaseThreshold}
+}
+
+func (s *priceInsightService) Refresh(ctx context.Context) (anomalies int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PriceInsightService.Refresh")
+	defer func() { tracing.End(span, err) }()
+
+	rules := models.PriceInsightRules{
+		IncreaseThreshold: s.increaseThreshold,
+		MinSample:         priceOutlierMinSample,
+		MinIncreaseUsers:  priceIncreaseMinUsers,
+		Since:             time.Now().AddDate(0, 0, -priceIncreaseWindowDays),
+	}
+
+	anomalies, err = s.repo.Refresh(ctx, currentMonth(), rules)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "Refresh",
+		}).Error("Failed to refresh price insights")
+		return 0, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"anomalies": anomalies,
+		"method":    "Refresh",
+	}).Info("Price insights refreshed")
+
+	return anomalies, nil
+}
+
+func (s *priceInsightService) GetInsights(ctx context.Context, req models.PriceInsightsRequest) (result *models.PriceInsights, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PriceInsightService.GetInsights")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"has_service_name": req.ServiceName != nil,
+		"has_user_id":      req.UserID != nil,
+		"has_kind":         req.Kind != nil,
+		"method":           "GetInsights",
+	}).Info("Getting price insights")
+
+	filter := models.PriceAnomalyFilter{ServiceName: req.ServiceName, Kind: req.Kind}
+	filter.UserID, err = parseOptionalUUID(req.UserID, "user_id")
+	if err != nil {
+		return nil, err
+	}
+	if req.Kind != nil {
+		switch *req.Kind {
+		case models.PriceAnomalyOutlier, models.PriceAnomalyIncrease, models.PriceAnomalyServiceIncrease:
+		default:
+			return nil, fmt.Errorf("%w: kind must be one of: outlier, increase, service_increase", ErrInvalidInput)
+		}
+	}
+
+	stats, err := s.repo.Stats(ctx, req.ServiceName)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetInsights",
+		}).Error("Failed to get price stats from repository")
+		return nil, err
+	}
+
+	anomalies, err := s.repo.Anomalies(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetInsights",
+		}).Error("Failed to get price anomalies from repository")
+		return nil, err
+	}
+
+	insights := &models.PriceInsights{
+		Services:  stats,
+		Anomalies: anomalies,
+	}
+	if insights.Services == nil {
+		insights.Services = []models.ServicePriceStats{}
+	}
+	if insights.Anomalies == nil {
+		insights.Anomalies = []models.PriceAnomaly{}
+	}
+	if len(stats) > 0 {
+		insights.ComputedAt = &stats[0].ComputedAt
+	}
+
+	return insights, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type RecommendationService interface {
+	// GetRecommendations предлагает пользователю способы сэкономить: перейти
+	// на годовой план, отказаться от сервиса, пересекающегося по категории с
+	// другим, или от подписки, отмеченной как неиспользуемая либо простаивающей.
+	GetRecommendations(ctx context.Context, userID string) (*models.Recommendations, error)
+}
+
+type recommendationService struct {
+	repo  repository.RecommendationRepository
+	users repository.UserRepository
+}
+
+func NewRecommendationService(repo repository.RecommendationRepository, users repository.UserRepository) RecommendationService {
+	logger.Log.WithField("component", "recommendation_service").Info("Creating new recommendation service")
+	return &recommendationService{repo: repo, users: users}
+}
+
+func (s *recommendationService) GetRecommendations(ctx context.Context, userID string) (result *models.Recommendations, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "RecommendationService.GetRecommendations")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+		"method":  "GetRecommendations",
+	}).Info("Building savings recommendations")
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	if _, err := s.users.GetByID(ctx, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+
+	candidates, err := s.repo.Candidates(ctx, ownerID, currentMonth())
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetRecommendations",
+		}).Error("Failed to get recommendation candidates from repository")
+		return nil, err
+	}
+
+	var recommendations []models.Recommendation
+	for _, candidate := range candidates {
+		switch {
+		case candidate.Unused:
+			recommendations = append(recommendations, savingsRecommendation(models.RecommendationUnused, candidate,
+				yearlyCost(candidate), fmt.Sprintf("%s is marked as unused; cancelling it saves its full cost", candidate.ServiceName)))
+		case candidate.Idle:
+			recommendations = append(recommendations, savingsRecommendation(models.RecommendationUnused, candidate,
+				yearlyCost(candidate), fmt.Sprintf("%s has not been used for over 30 days; cancelling it saves its full cost", candidate.ServiceName)))
+		}
+		if yearly, ok := yearlyPlanCost(candidate); ok && yearly < yearlyCost(candidate) {
+			recommendations = append(recommendations, savingsRecommendation(models.RecommendationYearlyPlan, candidate,
+				yearlyCost(candidate)-yearly, fmt.Sprintf("%s offers a yearly plan for %d, less than %d a year on the current plan",
+					candidate.ServiceName, *candidate.YearlyPrice, candidate.Price*12/candidate.IntervalMonths)))
+		}
+	}
+	recommendations = append(recommendations, categoryOverlaps(candidates)...)
+
+	// Подписка может попасть в несколько рекомендаций, но сэкономить на ней
+	// можно только один раз.
+	best := make(map[uuid.UUID]int)
+	for _, recommendation := range recommendations {
+		if recommendation.YearlySavings > best[recommendation.SubscriptionID] {
+			best[recommendation.SubscriptionID] = recommendation.YearlySavings
+		}
+	}
+
+	result = &models.Recommendations{
+		UserID:          ownerID,
+		Recommendations: recommendations,
+	}
+	if result.Recommendations == nil {
+		result.Recommendations = []models.Recommendation{}
+	}
+	sort.SliceStable(result.Recommendations, func(i, j int) bool {
+		return result.Recommendations[i].YearlySavings > result.Recommendations[j].YearlySavings
+	})
+	for _, savings := range best {
+		result.YearlySavings += savings
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id":         userID,
+		"recommendations": len(result.Recommendations),
+		"yearly_savings":  result.YearlySavings,
+		"method":          "GetRecommendations",
+	}).Info("Savings recommendations built successfully")
+
+	return result, nil
+}
+
+// categoryOverlaps ищет категории, в которых у пользователя несколько разных
+// сервисов, и предлагает оставить самый дорогой из используемых: остальные
+// советуется отменить. Подписки на один и тот же сервис — это дубликаты, а
+// не пересечение, и здесь не рассматриваются.
+func categoryOverlaps(candidates []models.RecommendationCandidate) []models.Recommendation {
+	groups := make(map[string][]models.RecommendationCandidate)
+	var keys []string
+	for _, candidate := range candidates {
+		if candidate.CategoryKey == nil {
+			continue
+		}
+		key := *candidate.CategoryKey
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], candidate)
+	}
+
+	var recommendations []models.Recommendation
+	for _, key := range keys {
+		group := groups[key]
+
+		var keep *models.RecommendationCandidate
+		for i := range group {
+			if group[i].Unused || group[i].Idle {
+				continue
+			}
+			if keep == nil || yearlyCost(group[i]) > yearlyCost(*keep) {
+				keep = &group[i]
+			}
+		}
+		if keep == nil {
+			continue
+		}
+
+		for _, candidate := range group {
+			if candidate.Unused || candidate.Idle || strings.EqualFold(candidate.ServiceName, keep.ServiceName) {
+				continue
+			}
+			category := "the same category"
+			if candidate.CategoryName != nil {
+				category = "category " + *candidate.CategoryName
+			}
+			recommendation := savingsRecommendation(models.RecommendationCategoryOverlap, candidate, yearlyCost(candidate),
+				fmt.Sprintf("%s overlaps with %s in %s; consider keeping only one", candidate.ServiceName, keep.ServiceName, category))
+			recommendation.Related = []uuid.UUID{keep.ID}
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+	return recommendations
+}
+
+func savingsRecommendation(kind string, candidate models.RecommendationCandidate, yearlySavings float64, message string) models.Recommendation {
+	return models.Recommendation{
+		Kind:              kind,
+		SubscriptionID:    candidate.ID,
+		ServiceName:       candidate.ServiceName,
+		Message:           message,
+		CurrentYearlyCost: int(math.Round(yearlyCost(candidate))),
+		MonthlySavings:    int(math.Round(yearlySavings / 12)),
+		YearlySavings:     int(math.Round(yearlySavings)),
+	}
+}
+
+// yearlyCost — доля пользователя в обычной цене подписки за год.
+func yearlyCost(candidate models.RecommendationCandidate) float64 {
+	return float64(candidate.Price) * 12 / float64(candidate.IntervalMonths) * candidate.ShareRatio
+}
+
+// yearlyPlanCost — доля пользователя в годовом плане сервиса из каталога,
+// если подписка оплачивается чаще раза в год.
+func yearlyPlanCost(candidate models.RecommendationCandidate) (float64, bool) {
+	if candidate.YearlyPrice == nil || candidate.IntervalMonths >= 12 {
+		return 0, false
+	}
+	return float64(*candidate.YearlyPrice) * candidate.ShareRatio, true
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Окно агрегатов: сводки за более ранние или поздние месяцы считаются по
+// подпискам.
+const (
+	rollupHistoryMonths = 36
+	rollupHorizonMonths = 24
+)
+
+type RollupService interface {
+	// Refresh пересчитывает устаревшие агрегаты. Если агрегатов нет или окно
+	// отстало от текущего месяца, они строятся заново.
+	Refresh(ctx context.Context) (refreshed int, rebuilt bool, err error)
+	Rebuild(ctx context.Context) error
+	Check(ctx context.Context) ([]models.RollupMismatch, error)
+}
+
+type rollupService struct {
+	repo repository.RollupRepository
+}
+
+func NewRollupService(repo repository.RollupRepository) RollupService {
+	logger.Log.WithField("component", "rollup_service").Info("Creating new rollup service")
+	return &rollupService{repo: repo}
+}
+
+func (s *rollupService) Refresh(ctx context.Context) (refreshed int, rebuilt bool, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "RollupService.Refresh")
+	defer func() { tracing.End(span, err) }()
+
+	state, err := s.repo.State(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, err
+	}
+	if err != nil || state.CoveredTo.Before(rollupWindowEnd()) {
+		return 0, true, s.Rebuild(ctx)
+	}
+
+	refreshed, err = s.repo.Refresh(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "Refresh",
+		}).Error("Failed to refresh spend rollups")
+		return 0, false, err
+	}
+
+	if refreshed > 0 {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"users":  refreshed,
+			"method": "Refresh",
+		}).Info("Spend rollups refreshed")
+	}
+
+	return refreshed, false, nil
+}
+
+func (s *rollupService) Rebuild(ctx context.Context) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "RollupService.Rebuild")
+	defer func() { tracing.End(span, err) }()
+
+	from := currentMonth().AddDate(0, -rollupHistoryMonths, 0)
+	to := rollupWindowEnd()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"from":   from.Format("2006-01"),
+		"to":     to.Format("2006-01"),
+		"method": "Rebuild",
+	}).Info("Rebuilding spend rollups")
+
+	if err := s.repo.Rebuild(ctx, from, to); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "Rebuild",
+		}).Error("Failed to rebuild spend rollups")
+		return err
+	}
+
+	logger.FromContext(ctx).WithField("method", "Rebuild").Info("Spend rollups rebuilt successfully")
+	return nil
+}
+
+func (s *rollupService) Check(ctx context.Context) (result []models.RollupMismatch, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "RollupService.Check")
+	defer func() { tracing.End(span, err) }()
+
+	mismatches, err := s.repo.Check(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: spend rollups are not built", ErrNotFound)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"mismatches": len(mismatches),
+		"method":     "Check",
+	}).Info("Spend rollups checked")
+
+	return mismatches, nil
+}
+
+func rollupWindowEnd() time.Time {
+	return currentMonth().AddDate(0, rollupHorizonMonths, 0)
+}
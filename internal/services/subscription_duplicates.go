@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// checkDuplicates ищет пересекающиеся подписки пользователя на тот же сервис.
+// В режиме warn возвращает предупреждение, в режиме reject — ErrConflict,
+// если дубликат не разрешен явно.
+func (s *subscriptionService) checkDuplicates(ctx context.Context, sub *models.Subscription, allow bool) ([]string, error) {
+	if s.duplicateStrictness == models.DuplicatesOff {
+		return nil, nil
+	}
+
+	duplicates, err := s.duplicates.FindOverlapping(ctx, sub)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"user_id": sub.UserID.String(),
+			"method":  "checkDuplicates",
+		}).Error("Failed to look up duplicate subscriptions")
+		return nil, err
+	}
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		ids = append(ids, duplicate.ID.String())
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id":      sub.UserID.String(),
+		"service_name": sub.ServiceName,
+		"duplicates":   len(ids),
+		"strictness":   s.duplicateStrictness,
+		"allowed":      allow,
+		"method":       "checkDuplicates",
+	}).Warn("Possible duplicate subscription")
+
+	if s.duplicateStrictness == models.DuplicatesReject && !allow {
+		return nil, fmt.Errorf("%w: subscription to %s overlaps existing subscriptions %s; set allow_duplicate to create it anyway",
+			ErrConflict, sub.ServiceName, strings.Join(ids, ", "))
+	}
+	return []string{fmt.Sprintf("possible duplicate of subscriptions %s", strings.Join(ids, ", "))}, nil
+}
+
+func (s *subscriptionService) ListDuplicates(ctx context.Context, userID *string) (result []models.DuplicatePair, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.ListDuplicates")
+	defer func() { tracing.End(span, err) }()
+
+	ownerID, err := parseOptionalUUID(userID, "user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := s.duplicates.List(ctx, ownerID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListDuplicates",
+		}).Error("Failed to list duplicate subscriptions from repository")
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"pairs":  len(pairs),
+		"method": "ListDuplicates",
+	}).Info("Duplicate subscriptions listed successfully")
+
+	return pairs, nil
+}
+
+func (s *subscriptionService) MergeSubscriptions(ctx context.Context, req models.MergeSubscriptionsRequest) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.MergeSubscriptions")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"keep_id":  req.KeepID,
+		"merge_id": req.MergeID,
+		"method":   "MergeSubscriptions",
+	}).Info("Merging subscriptions")
+
+	keepID, err := uuid.Parse(req.KeepID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid keep_id", ErrInvalidInput)
+	}
+	mergeID, err := uuid.Parse(req.MergeID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid merge_id", ErrInvalidInput)
+	}
+	if keepID == mergeID {
+		return nil, fmt.Errorf("%w: keep_id and merge_id must differ", ErrInvalidInput)
+	}
+
+	keep, err := s.lifecycleSubscription(ctx, req.KeepID)
+	if err != nil {
+		return nil, err
+	}
+	merge, err := s.lifecycleSubscription(ctx, req.MergeID)
+	if err != nil {
+		return nil, err
+	}
+	if keep.UserID != merge.UserID {
+		return nil, fmt.Errorf("%w: subscriptions belong to different users", ErrInvalidInput)
+	}
+	if !sameSubscriptionService(keep, merge) {
+		return nil, fmt.Errorf("%w: subscriptions are for different services", ErrInvalidInput)
+	}
+	if !periodsAdjoin(keep, merge) {
+		return nil, fmt.Errorf("%w: subscription periods neither overlap nor adjoin", ErrInvalidInput)
+	}
+
+	record, err := s.duplicates.Merge(ctx, keepID, mergeID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "MergeSubscriptions",
+		}).Error("Failed to merge subscriptions in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription was deleted during merge", ErrNotFound)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"keep_id":  req.KeepID,
+		"merge_id": req.MergeID,
+		"record":   record.ID.String(),
+		"method":   "MergeSubscriptions",
+	}).Info("Subscriptions merged successfully")
+
+	return s.lifecycleSubscription(ctx, req.KeepID)
+}
+
+func (s *subscriptionService) ListMerges(ctx context.Context, id string) (result []models.SubscriptionMerge, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.ListMerges")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merges, err := s.duplicates.ListMerges(ctx, subscription.ID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListMerges",
+		}).Error("Failed to list subscription merges from repository")
+		return nil, err
+	}
+
+	return merges, nil
+}
+
+// periodsAdjoin проверяет, что месячные периоды a и b пересекаются или идут
+// подряд: иначе объединенный период захватил бы месяцы без подписки.
+func periodsAdjoin(a, b *models.Subscription) bool {
+	if a.EndDate != nil && a.EndDate.AddDate(0, 1, 0).Before(b.StartDate) {
+		return false
+	}
+	if b.EndDate != nil && b.EndDate.AddDate(0, 1, 0).Before(a.StartDate) {
+		return false
+	}
+	return true
+}
+
+// sameSubscriptionService повторяет правило sameService из репозитория.
+func sameSubscriptionService(a, b *models.Subscription) bool {
+	if a.ServiceID != nil && b.ServiceID != nil {
+		return *a.ServiceID == *b.ServiceID
+	}
+	return strings.EqualFold(a.ServiceName, b.ServiceName)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// allowedTransitions — допустимые переходы из текущего статуса подписки.
+// Переходы в trialing и expired происходят сами по фазам и end_date, а
+// paused -> active выполняется возобновлением паузы.
+var allowedTransitions = map[string][]string{
+	models.StatusTrialing:        {models.StatusPaused, models.StatusCancelScheduled, models.StatusCancelled},
+	models.StatusActive:          {models.StatusPaused, models.StatusCancelScheduled, models.StatusCancelled},
+	models.StatusPaused:          {models.StatusPaused, models.StatusCancelScheduled, models.StatusCancelled},
+	models.StatusCancelScheduled: {models.StatusActive, models.StatusPaused, models.StatusCancelled},
+	models.StatusCancelled:       {models.StatusActive},
+	models.StatusExpired:         {models.StatusActive},
+}
+
+func checkTransition(from, to string) error {
+	for _, allowed := range allowedTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: transition from %s to %s is not allowed", ErrConflict, from, to)
+}
+
+// endDateStatus — статус, который задают state и end_date. Пустая строка
+// означает, что статус определяется паузами и фазами.
+func endDateStatus(state string, endDate *time.Time) string {
+	switch {
+	case state == models.StatusCancelled:
+		return models.StatusCancelled
+	case endDate != nil && endDate.Before(currentMonth()):
+		if state == models.StatusCancelScheduled {
+			return models.StatusCancelled
+		}
+		return models.StatusExpired
+	case state == models.StatusCancelScheduled && endDate != nil:
+		return models.StatusCancelScheduled
+	}
+	return ""
+}
+
+// checkEndDateUpdate не дает PUT сменить статус подписки через end_date:
+// такие изменения идут через эндпоинты жизненного цикла и записываются
+// переходами.
+func checkEndDateUpdate(subscription *models.Subscription, endDate *time.Time) error {
+	from := endDateStatus(subscription.State, subscription.EndDate)
+	to := endDateStatus(subscription.State, endDate)
+	if from == to {
+		return nil
+	}
+	if to == "" {
+		to = models.StatusActive
+	}
+	return fmt.Errorf("%w: changing end_date would move the subscription from %s to %s, use the cancel, cancel-at-period-end or reactivate endpoints",
+		ErrConflict, subscription.Status, to)
+}
+
+func validStatus(status string) bool {
+	_, ok := allowedTransitions[status]
+	return ok
+}
+
+func (s *subscriptionService) CancelSubscription(ctx context.Context, id string) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.CancelSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(subscription.Status, models.StatusCancelled); err != nil {
+		return nil, err
+	}
+
+	// Текущий месяц уже оплачен, поэтому он остается последним.
+	month := currentMonth()
+	if subscription.StartDate.After(month) {
+		return nil, fmt.Errorf("%w: subscription has not started yet, delete it instead", ErrConflict)
+	}
+
+	return s.changeState(ctx, subscription, &models.StateChange{
+		State:   models.StatusCancelled,
+		EndDate: &month,
+	})
+}
+
+func (s *subscriptionService) CancelAtPeriodEnd(ctx context.Context, id string, req models.CancelAtPeriodEndRequest) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.CancelAtPeriodEnd")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(subscription.Status, models.StatusCancelScheduled); err != nil {
+		return nil, err
+	}
+
+	endDate := currentMonth()
+	if req.EndDate != nil {
+		endDate, err = parseMonthOrCurrent(req.EndDate, "end_date")
+		if err != nil {
+			return nil, err
+		}
+		if endDate.Before(currentMonth()) {
+			return nil, fmt.Errorf("%w: end_date must not be in the past", ErrInvalidInput)
+		}
+	}
+	if endDate.Before(subscription.StartDate) {
+		if req.EndDate != nil {
+			return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidInput)
+		}
+		endDate = subscription.StartDate
+	}
+
+	return s.changeState(ctx, subscription, &models.StateChange{
+		State:   models.StatusCancelScheduled,
+		EndDate: &endDate,
+	})
+}
+
+func (s *subscriptionService) ReactivateSubscription(ctx context.Context, id string) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.ReactivateSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(subscription.Status, models.StatusActive); err != nil {
+		return nil, err
+	}
+
+	change := &models.StateChange{State: models.StatusActive}
+	if subscription.Status != models.StatusCancelScheduled {
+		// Оплата возобновляется с текущего месяца, пропущенные месяцы не начисляются.
+		month := currentMonth()
+		change.ResumeFrom = &month
+	}
+
+	return s.changeState(ctx, subscription, change)
+}
+
+func (s *subscriptionService) ListTransitions(ctx context.Context, id string) (result []models.SubscriptionTransition, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.ListTransitions")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, err := s.repo.ListTransitions(ctx, subscription.ID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "ListTransitions",
+		}).Error("Failed to list subscription transitions from repository")
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+func (s *subscriptionService) lifecycleSubscription(ctx context.Context, id string) (*models.Subscription, error) {
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	subscription, err := s.repo.GetByID(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *subscriptionService) changeState(ctx context.Context, subscription *models.Subscription, change *models.StateChange) (*models.Subscription, error) {
+	change.FromState = subscription.State
+	change.FromEndDate = subscription.EndDate
+	change.Transition = models.SubscriptionTransition{
+		FromStatus: subscription.Status,
+		ToStatus:   change.State,
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":          subscription.ID.String(),
+		"from_status": subscription.Status,
+		"to_status":   change.State,
+		"method":      "changeState",
+	}).Info("Changing subscription status")
+
+	if err := s.repo.ChangeState(ctx, subscription.ID, change); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     subscription.ID.String(),
+			"method": "changeState",
+		}).Error("Failed to change subscription state in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, subscription.ID)
+		}
+		if errors.Is(err, repository.ErrStaleState) {
+			return nil, fmt.Errorf("%w: subscription was modified concurrently, retry", ErrConflict)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":          subscription.ID.String(),
+		"from_status": subscription.Status,
+		"to_status":   change.State,
+		"method":      "changeState",
+	}).Info("Subscription status changed successfully")
+
+	return s.lifecycleSubscription(ctx, subscription.ID.String())
+}
@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"subscribe_project/internal/models"
 	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
 	"subscribe_project/pkg/logger"
 	"time"
 
@@ -17,21 +21,57 @@ type SubscriptionService interface {
 	GetSubscription(ctx context.Context, id string) (*models.Subscription, error)
 	UpdateSubscription(ctx context.Context, id string, req models.UpdateSubscriptionRequest) error
 	DeleteSubscription(ctx context.Context, id string) error
-	ListSubscriptions(ctx context.Context, page, limit int) ([]models.Subscription, error)
+	ListSubscriptions(ctx context.Context, req models.ListSubscriptionsRequest) ([]models.Subscription, error)
 	GetSummary(ctx context.Context, req models.SummaryRequest) (*models.SubscriptionSummary, error)
+	// CancelSubscription отменяет подписку сразу: текущий месяц становится последним.
+	CancelSubscription(ctx context.Context, id string) (*models.Subscription, error)
+	// CancelAtPeriodEnd планирует отмену после указанного (по умолчанию текущего) месяца.
+	CancelAtPeriodEnd(ctx context.Context, id string, req models.CancelAtPeriodEndRequest) (*models.Subscription, error)
+	// ReactivateSubscription снимает запланированную отмену или возобновляет
+	// отмененную либо истекшую подписку с текущего месяца.
+	ReactivateSubscription(ctx context.Context, id string) (*models.Subscription, error)
+	ListTransitions(ctx context.Context, id string) ([]models.SubscriptionTransition, error)
+	// ListDuplicates находит пары пересекающихся подписок пользователя на один сервис.
+	ListDuplicates(ctx context.Context, userID *string) ([]models.DuplicatePair, error)
+	// MergeSubscriptions объединяет дубликат с оставляемой подпиской.
+	MergeSubscriptions(ctx context.Context, req models.MergeSubscriptionsRequest) (*models.Subscription, error)
+	ListMerges(ctx context.Context, id string) ([]models.SubscriptionMerge, error)
+	// RecordUsage записывает использование подписки вручную или от интеграции.
+	RecordUsage(ctx context.Context, id string, req models.RecordUsageRequest) (*models.SubscriptionUsage, error)
+	GetUsage(ctx context.Context, id string) (*models.SubscriptionUsage, error)
 }
 
 type subscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo       repository.SubscriptionRepository
+	catalog    repository.CatalogRepository
+	categories repository.CategoryRepository
+	users      repository.UserRepository
+	methods    repository.PaymentMethodRepository
+	duplicates repository.DuplicateRepository
+	usage      repository.UsageRepository
+	// duplicateStrictness — реакция на возможный дубликат: off, warn или reject.
+	duplicateStrictness string
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepository) SubscriptionService {
+func NewSubscriptionService(repo repository.SubscriptionRepository, catalog repository.CatalogRepository, categories repository.CategoryRepository, users repository.UserRepository, methods repository.PaymentMethodRepository, duplicates repository.DuplicateRepository, usage repository.UsageRepository, duplicateStrictness string) SubscriptionService {
 	logger.Log.WithField("component", "subscription_service").Info("Creating new subscription service")
-	return &subscriptionService{repo: repo}
+	return &subscriptionService{
+		repo:                repo,
+		catalog:             catalog,
+		categories:          categories,
+		users:               users,
+		methods:             methods,
+		duplicates:          duplicates,
+		usage:               usage,
+		duplicateStrictness: duplicateStrictness,
+	}
 }
 
-func (s *subscriptionService) CreateSubscription(ctx context.Context, req models.CreateSubscriptionRequest) (*models.Subscription, error) {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) CreateSubscription(ctx context.Context, req models.CreateSubscriptionRequest) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.CreateSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method":       "CreateSubscription",
 		"service_name": req.ServiceName,
 		"user_id":      req.UserID,
@@ -41,7 +81,7 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":   err.Error(),
 			"user_id": req.UserID,
 			"method":  "CreateSubscription",
@@ -49,9 +89,20 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 		return nil, fmt.Errorf("invalid user_id: %w", err)
 	}
 
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"user_id": req.UserID,
+				"method":  "CreateSubscription",
+			}).Warn("Subscription user does not exist")
+			return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalidInput, userID)
+		}
+		return nil, err
+	}
+
 	startDate, err := time.Parse("01-2006", req.StartDate)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":      err.Error(),
 			"start_date": req.StartDate,
 			"method":     "CreateSubscription",
@@ -63,7 +114,7 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 	if req.EndDate != nil {
 		ed, err := time.Parse("01-2006", *req.EndDate)
 		if err != nil {
-			logger.Log.WithFields(logrus.Fields{
+			logger.FromContext(ctx).WithFields(logrus.Fields{
 				"error":    err.Error(),
 				"end_date": *req.EndDate,
 				"method":   "CreateSubscription",
@@ -71,18 +122,75 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 			return nil, fmt.Errorf("invalid end_date format: %w", err)
 		}
 		endDate = &ed
-		logger.Log.WithField("end_date", ed.Format("2006-01-02")).Debug("Parsed end date")
+		logger.FromContext(ctx).WithField("end_date", ed.Format("2006-01-02")).Debug("Parsed end date")
 	}
 
 	subscription := &models.Subscription{
 		ServiceName: req.ServiceName,
 		Price:       req.Price,
 		UserID:      userID,
+		SplitRule:   models.SplitRuleEqual,
 		StartDate:   startDate,
 		EndDate:     endDate,
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	subscription.BillingInterval = models.BillingMonthly
+	if req.BillingInterval != nil {
+		if err := validateBillingInterval(*req.BillingInterval); err != nil {
+			return nil, err
+		}
+		subscription.BillingInterval = *req.BillingInterval
+	}
+
+	subscription.State = models.StatusActive
+	subscription.Status = models.StatusActive
+	if endDate != nil && endDate.Before(currentMonth()) {
+		subscription.Status = models.StatusExpired
+	}
+
+	service, err := resolveCatalogService(ctx, s.catalog, req.ServiceName)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":        err.Error(),
+			"service_name": req.ServiceName,
+			"method":       "CreateSubscription",
+		}).Error("Failed to resolve service in catalog")
+		return nil, err
+	}
+	if service != nil {
+		subscription.ServiceName = service.Name
+		subscription.ServiceID = &service.ID
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"service_name": req.ServiceName,
+			"canonical":    service.Name,
+			"service_id":   service.ID.String(),
+			"method":       "CreateSubscription",
+		}).Debug("Service name resolved via catalog")
+	}
+
+	subscription.CategoryID, err = s.subscriptionCategory(ctx, req.CategoryID, service)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Tags, err = normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PaymentMethodID != nil && *req.PaymentMethodID != "" {
+		subscription.PaymentMethodID, err = s.subscriptionPaymentMethod(ctx, *req.PaymentMethodID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subscription.Warnings, err = s.checkDuplicates(ctx, subscription, req.AllowDuplicate)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"subscription_id": subscription.ID.String(),
 		"start_date":      startDate.Format("2006-01-02"),
 		"has_end_date":    endDate != nil,
@@ -91,15 +199,18 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 
 	err = s.repo.Create(ctx, subscription)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":           err.Error(),
 			"subscription_id": subscription.ID.String(),
 			"method":          "CreateSubscription",
 		}).Error("Failed to create subscription in repository")
+		if errors.Is(err, repository.ErrInvalidReference) {
+			return nil, fmt.Errorf("%w: user, service or category no longer exists", ErrInvalidInput)
+		}
 		return nil, err
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"subscription_id": subscription.ID.String(),
 		"service_name":    subscription.ServiceName,
 		"price":           subscription.Price,
@@ -110,15 +221,18 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req models
 	return subscription, nil
 }
 
-func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*models.Subscription, error) {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (result *models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.GetSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method": "GetSubscription",
 		"id":     id,
 	}).Info("Getting subscription")
 
 	subscriptionID, err := uuid.Parse(id)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "GetSubscription",
@@ -128,7 +242,7 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*
 
 	subscription, err := s.repo.GetByID(ctx, subscriptionID)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "GetSubscription",
@@ -136,7 +250,7 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*
 		return nil, err
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"id":           subscription.ID.String(),
 		"service_name": subscription.ServiceName,
 		"method":       "GetSubscription",
@@ -145,20 +259,25 @@ func (s *subscriptionService) GetSubscription(ctx context.Context, id string) (*
 	return subscription, nil
 }
 
-func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string, req models.UpdateSubscriptionRequest) error {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string, req models.UpdateSubscriptionRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.UpdateSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method": "UpdateSubscription",
 		"id":     id,
 		"fields_to_update": map[string]interface{}{
 			"service_name": req.ServiceName != nil,
 			"price":        req.Price != nil,
 			"end_date":     req.EndDate != nil,
+			"billing":      req.BillingInterval != nil,
+			"unused":       req.Unused != nil,
 		},
 	}).Info("Updating subscription")
 
 	subscriptionID, err := uuid.Parse(id)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "UpdateSubscription",
@@ -166,14 +285,75 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string,
 		return fmt.Errorf("invalid subscription id: %w", err)
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	if req.ServiceName != nil {
+		service, err := resolveCatalogService(ctx, s.catalog, *req.ServiceName)
+		if err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"error":        err.Error(),
+				"service_name": *req.ServiceName,
+				"method":       "UpdateSubscription",
+			}).Error("Failed to resolve service in catalog")
+			return err
+		}
+		if service != nil {
+			req.ServiceName = &service.Name
+			req.ServiceID = &service.ID
+		}
+	}
+
+	if req.BillingInterval != nil {
+		if err := validateBillingInterval(*req.BillingInterval); err != nil {
+			return err
+		}
+	}
+
+	if req.CategoryID != nil && *req.CategoryID != "" {
+		req.CategoryUUID, err = s.subscriptionCategory(ctx, req.CategoryID, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.EndDate != nil {
+		current, err := s.lifecycleSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+		var endDate *time.Time
+		if *req.EndDate != "" {
+			parsed, err := time.Parse("01-2006", *req.EndDate)
+			if err != nil {
+				return fmt.Errorf("%w: invalid end_date format, expected MM-YYYY", ErrInvalidInput)
+			}
+			endDate = &parsed
+		}
+		if err := checkEndDateUpdate(current, endDate); err != nil {
+			return err
+		}
+	}
+
+	if req.PaymentMethodID != nil && *req.PaymentMethodID != "" {
+		current, err := s.repo.GetByID(ctx, subscriptionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+			}
+			return err
+		}
+		req.PaymentMethodUUID, err = s.subscriptionPaymentMethod(ctx, *req.PaymentMethodID, current.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"id":     id,
 		"method": "UpdateSubscription",
 	}).Debug("Attempting to update subscription in repository")
 
 	err = s.repo.Update(ctx, subscriptionID, &req)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "UpdateSubscription",
@@ -181,7 +361,7 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string,
 		return err
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"id":     id,
 		"method": "UpdateSubscription",
 	}).Info("Subscription updated successfully")
@@ -189,15 +369,18 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, id string,
 	return nil
 }
 
-func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string) error {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.DeleteSubscription")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method": "DeleteSubscription",
 		"id":     id,
 	}).Info("Deleting subscription")
 
 	subscriptionID, err := uuid.Parse(id)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "DeleteSubscription",
@@ -205,14 +388,14 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string)
 		return fmt.Errorf("invalid subscription id: %w", err)
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"id":     id,
 		"method": "DeleteSubscription",
 	}).Debug("Attempting to delete subscription from repository")
 
 	err = s.repo.Delete(ctx, subscriptionID)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"id":     id,
 			"method": "DeleteSubscription",
@@ -220,7 +403,7 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string)
 		return err
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"id":     id,
 		"method": "DeleteSubscription",
 	}).Info("Subscription deleted successfully")
@@ -228,8 +411,13 @@ func (s *subscriptionService) DeleteSubscription(ctx context.Context, id string)
 	return nil
 }
 
-func (s *subscriptionService) ListSubscriptions(ctx context.Context, page, limit int) ([]models.Subscription, error) {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) ListSubscriptions(ctx context.Context, req models.ListSubscriptionsRequest) (result []models.Subscription, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.ListSubscriptions")
+	defer func() { tracing.End(span, err) }()
+
+	page, limit := req.Page, req.Limit
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method": "ListSubscriptions",
 		"page":   page,
 		"limit":  limit,
@@ -237,23 +425,57 @@ func (s *subscriptionService) ListSubscriptions(ctx context.Context, page, limit
 
 	if limit <= 0 {
 		limit = 10
-		logger.Log.WithField("new_limit", limit).Debug("Limit adjusted to default")
+		logger.FromContext(ctx).WithField("new_limit", limit).Debug("Limit adjusted to default")
 	}
 	if page <= 0 {
 		page = 1
-		logger.Log.WithField("new_page", page).Debug("Page adjusted to default")
+		logger.FromContext(ctx).WithField("new_page", page).Debug("Page adjusted to default")
 	}
 	offset := (page - 1) * limit
 
-	logger.Log.WithFields(logrus.Fields{
-		"limit":  limit,
-		"offset": offset,
-		"method": "ListSubscriptions",
+	filter := models.SubscriptionFilter{Limit: limit, Offset: offset, Tag: req.Tag}
+	if req.PaymentMethodID != nil {
+		methodID, err := uuid.Parse(*req.PaymentMethodID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid payment_method_id", ErrInvalidInput)
+		}
+		filter.PaymentMethodID = &methodID
+	}
+	if req.Status != nil {
+		if !validStatus(*req.Status) {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, *req.Status)
+		}
+		filter.Status = req.Status
+	}
+	if req.UserID != nil {
+		userID, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid user_id", ErrInvalidInput)
+		}
+		filter.UserID = &userID
+	}
+	if req.CategoryID != nil {
+		categoryID, err := uuid.Parse(*req.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid category_id", ErrInvalidInput)
+		}
+		filter.CategoryID = &categoryID
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"limit":        limit,
+		"offset":       offset,
+		"has_user":     filter.UserID != nil,
+		"has_category": filter.CategoryID != nil,
+		"has_tag":      filter.Tag != nil,
+		"has_status":   filter.Status != nil,
+		"has_method":   filter.PaymentMethodID != nil,
+		"method":       "ListSubscriptions",
 	}).Debug("Fetching subscriptions from repository")
 
-	subscriptions, err := s.repo.List(ctx, limit, offset)
+	subscriptions, err := s.repo.List(ctx, filter)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":  err.Error(),
 			"page":   page,
 			"limit":  limit,
@@ -262,7 +484,7 @@ func (s *subscriptionService) ListSubscriptions(ctx context.Context, page, limit
 		return nil, err
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"count":        len(subscriptions),
 		"method":       "ListSubscriptions",
 		"current_page": page,
@@ -272,8 +494,11 @@ func (s *subscriptionService) ListSubscriptions(ctx context.Context, page, limit
 	return subscriptions, nil
 }
 
-func (s *subscriptionService) GetSummary(ctx context.Context, req models.SummaryRequest) (*models.SubscriptionSummary, error) {
-	logger.Log.WithFields(logrus.Fields{
+func (s *subscriptionService) GetSummary(ctx context.Context, req models.SummaryRequest) (result *models.SubscriptionSummary, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.GetSummary")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"method":     "GetSummary",
 		"start_date": req.StartDate,
 		"end_date":   req.EndDate,
@@ -283,9 +508,24 @@ func (s *subscriptionService) GetSummary(ctx context.Context, req models.Summary
 		},
 	}).Info("Getting subscription summary")
 
+	if req.ServiceName != nil {
+		service, err := resolveCatalogService(ctx, s.catalog, *req.ServiceName)
+		if err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"error":        err.Error(),
+				"service_name": *req.ServiceName,
+				"method":       "GetSummary",
+			}).Error("Failed to resolve service in catalog")
+			return nil, err
+		}
+		if service != nil {
+			req.ServiceID = &service.ID
+		}
+	}
+
 	totalCost, err := s.repo.GetSummary(ctx, req)
 	if err != nil {
-		logger.Log.WithFields(logrus.Fields{
+		logger.FromContext(ctx).WithFields(logrus.Fields{
 			"error":        err.Error(),
 			"start_date":   req.StartDate,
 			"end_date":     req.EndDate,
@@ -300,7 +540,37 @@ func (s *subscriptionService) GetSummary(ctx context.Context, req models.Summary
 		TotalCost: totalCost,
 	}
 
-	logger.Log.WithFields(logrus.Fields{
+	totalUses, err := s.usage.SummaryUses(ctx, req)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetSummary",
+		}).Error("Failed to get summary usage from repository")
+		return nil, err
+	}
+	summary.TotalUses = math.Round(totalUses*100) / 100
+	if totalUses > 0 {
+		costPerUse := math.Round(float64(totalCost)/totalUses*100) / 100
+		summary.CostPerUse = &costPerUse
+	}
+
+	if req.GroupBy != nil {
+		if *req.GroupBy != models.SummaryGroupByCategory && *req.GroupBy != models.SummaryGroupByTag {
+			return nil, fmt.Errorf("%w: group_by must be one of: category, tag", ErrInvalidInput)
+		}
+
+		summary.Groups, err = s.repo.GetSummaryGroups(ctx, req)
+		if err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"error":    err.Error(),
+				"group_by": *req.GroupBy,
+				"method":   "GetSummary",
+			}).Error("Failed to get grouped subscription summary from repository")
+			return nil, err
+		}
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
 		"total_cost": totalCost,
 		"method":     "GetSummary",
 		"start_date": req.StartDate,
@@ -309,3 +579,69 @@ func (s *subscriptionService) GetSummary(ctx context.Context, req models.Summary
 
 	return summary, nil
 }
+
+// subscriptionCategory возвращает категорию подписки. Явно указанная категория
+// должна существовать; без нее категория берется из каталога по имени.
+func (s *subscriptionService) subscriptionCategory(ctx context.Context, categoryID *string, service *models.Service) (*uuid.UUID, error) {
+	if categoryID != nil {
+		id, err := uuid.Parse(*categoryID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid category_id", ErrInvalidInput)
+		}
+		if _, err := s.categories.GetByID(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: category %s does not exist", ErrInvalidInput, id)
+			}
+			return nil, err
+		}
+		return &id, nil
+	}
+
+	if service == nil || service.Category == nil {
+		return nil, nil
+	}
+
+	category, err := s.categories.FindByName(ctx, *service.Category)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).WithField("category", *service.Category).Debug("Catalog category has no matching category")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category.ID, nil
+}
+
+// subscriptionPaymentMethod проверяет, что способ оплаты существует и
+// принадлежит владельцу подписки.
+func (s *subscriptionService) subscriptionPaymentMethod(ctx context.Context, methodID string, ownerID uuid.UUID) (*uuid.UUID, error) {
+	id, err := uuid.Parse(methodID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid payment_method_id", ErrInvalidInput)
+	}
+
+	method, err := s.methods.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: payment method %s does not exist", ErrInvalidInput, id)
+		}
+		return nil, err
+	}
+	if method.UserID != ownerID {
+		return nil, fmt.Errorf("%w: payment method %s belongs to another user", ErrInvalidInput, id)
+	}
+	return &method.ID, nil
+}
+
+func validateBillingInterval(interval string) error {
+	switch interval {
+	case models.BillingMonthly, models.BillingQuarterly, models.BillingYearly:
+		return nil
+	}
+	return fmt.Errorf("%w: billing_interval must be one of: monthly, quarterly, yearly", ErrInvalidInput)
+}
+
+func currentMonth() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
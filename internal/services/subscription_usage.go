@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// usageHistoryMonths — глубина помесячной истории использования, включая текущий месяц.
+const usageHistoryMonths = 12
+
+// usageClockSkew — насколько время использования от интеграций может
+// опережать часы сервиса.
+const usageClockSkew = 5 * time.Minute
+
+func (s *subscriptionService) RecordUsage(ctx context.Context, id string, req models.RecordUsageRequest) (result *models.SubscriptionUsage, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.RecordUsage")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"count":  req.Count,
+		"method": "RecordUsage",
+	}).Info("Recording subscription usage")
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count := req.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("%w: count must be positive", ErrInvalidInput)
+	}
+
+	usedAt := time.Now().UTC()
+	if req.UsedAt != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.UsedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid used_at format, expected RFC 3339", ErrInvalidInput)
+		}
+		usedAt = parsed.UTC()
+	}
+	if usedAt.After(time.Now().Add(usageClockSkew)) {
+		return nil, fmt.Errorf("%w: used_at must not be in the future", ErrInvalidInput)
+	}
+	if usedAt.Before(subscription.StartDate) {
+		return nil, fmt.Errorf("%w: used_at is before the subscription start", ErrInvalidInput)
+	}
+	// end_date — последний оплаченный месяц, подписка действует до его конца.
+	if subscription.EndDate != nil && !usedAt.Before(subscription.EndDate.AddDate(0, 1, 0)) {
+		return nil, fmt.Errorf("%w: used_at is after the subscription end", ErrInvalidInput)
+	}
+	if subscription.State == models.StatusCancelled && subscription.CancelledAt != nil && usedAt.After(*subscription.CancelledAt) {
+		return nil, fmt.Errorf("%w: used_at is after the subscription was cancelled", ErrInvalidInput)
+	}
+
+	if err := s.usage.Record(ctx, subscription.ID, usedAt, count); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "RecordUsage",
+		}).Error("Failed to record usage in repository")
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscription.ID.String(),
+		"used_at":         usedAt.Format(time.RFC3339),
+		"count":           count,
+		"method":          "RecordUsage",
+	}).Info("Subscription usage recorded successfully")
+
+	return s.GetUsage(ctx, id)
+}
+
+func (s *subscriptionService) GetUsage(ctx context.Context, id string) (result *models.SubscriptionUsage, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "SubscriptionService.GetUsage")
+	defer func() { tracing.End(span, err) }()
+
+	subscription, err := s.lifecycleSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	to := currentMonth()
+	usage, err := s.usage.Get(ctx, subscription.ID, to.AddDate(0, 1-usageHistoryMonths, 0), to)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetUsage",
+		}).Error("Failed to get usage from repository")
+		return nil, err
+	}
+	if usage.Months == nil {
+		usage.Months = []models.UsageMonth{}
+	}
+
+	return usage, nil
+}
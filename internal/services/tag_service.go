@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const maxTagLength = 50
+
+type TagService interface {
+	CreateTag(ctx context.Context, req models.CreateTagRequest) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id string) error
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	// SetSubscriptionTags заменяет теги подписки; неизвестные теги создаются.
+	SetSubscriptionTags(ctx context.Context, subscriptionID string, req models.SetSubscriptionTagsRequest) ([]string, error)
+}
+
+type tagService struct {
+	repo        repository.TagRepository
+	invalidator CacheInvalidator
+}
+
+func NewTagService(repo repository.TagRepository, invalidator CacheInvalidator) TagService {
+	logger.Log.WithField("component", "tag_service").Info("Creating new tag service")
+	return &tagService{repo: repo, invalidator: invalidator}
+}
+
+func (s *tagService) CreateTag(ctx context.Context, req models.CreateTagRequest) (result *models.Tag, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TagService.CreateTag")
+	defer func() { tracing.End(span, err) }()
+
+	names, err := normalizeTags([]string{req.Name})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	tag := &models.Tag{Name: names[0]}
+	if err := s.repo.Create(ctx, tag); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "CreateTag",
+		}).Error("Failed to create tag in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: tag %q already exists", ErrConflict, tag.Name)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"tag_id": tag.ID.String(),
+		"method": "CreateTag",
+	}).Info("Tag created successfully")
+
+	return tag, nil
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TagService.DeleteTag")
+	defer func() { tracing.End(span, err) }()
+
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid tag id", ErrInvalidInput)
+	}
+
+	if err := s.repo.Delete(ctx, tagID); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"id":     id,
+			"method": "DeleteTag",
+		}).Error("Failed to delete tag from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: tag %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"id":     id,
+		"method": "DeleteTag",
+	}).Info("Tag deleted successfully")
+
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *tagService) ListTags(ctx context.Context) (result []models.Tag, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TagService.ListTags")
+	defer func() { tracing.End(span, err) }()
+
+	tags, err := s.repo.List(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListTags",
+		}).Error("Failed to list tags from repository")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (s *tagService) SetSubscriptionTags(ctx context.Context, subscriptionID string, req models.SetSubscriptionTagsRequest) (result []string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TagService.SetSubscriptionTags")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subscription id", ErrInvalidInput)
+	}
+
+	names, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"tags":            len(names),
+		"method":          "SetSubscriptionTags",
+	}).Info("Replacing subscription tags")
+
+	if err := s.repo.SetForSubscription(ctx, id, names); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":           err.Error(),
+			"subscription_id": subscriptionID,
+			"method":          "SetSubscriptionTags",
+		}).Error("Failed to replace subscription tags in repository")
+		if errors.Is(err, repository.ErrInvalidReference) || errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: subscription %s", ErrNotFound, subscriptionID)
+		}
+		return nil, err
+	}
+
+	s.invalidator.InvalidateSubscription(ctx, id)
+	return names, nil
+}
+
+// normalizeTags убирает лишние пробелы и дубли без учета регистра,
+// сохраняя написание первого вхождения.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	result := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		name := strings.Join(strings.Fields(tag), " ")
+		if name == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidInput, name, maxTagLength)
+		}
+
+		key := strings.ToLower(name)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, name)
+	}
+	return result, nil
+}
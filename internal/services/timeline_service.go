@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type TimelineService interface {
+	// GetTimeline строит по каждому сервису пользователя периоды подписок,
+	// их пересечения и разрывы и оценивает переплату за пересечения.
+	GetTimeline(ctx context.Context, userID string) (*models.Timeline, error)
+}
+
+type timelineService struct {
+	repo  repository.TimelineRepository
+	users repository.UserRepository
+}
+
+func NewTimelineService(repo repository.TimelineRepository, users repository.UserRepository) TimelineService {
+	logger.Log.WithField("component", "timeline_service").Info("Creating new timeline service")
+	return &timelineService{repo: repo, users: users}
+}
+
+func (s *timelineService) GetTimeline(ctx context.Context, userID string) (result *models.Timeline, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TimelineService.GetTimeline")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id": userID,
+		"method":  "GetTimeline",
+	}).Info("Building subscription timeline")
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+	if _, err := s.users.GetByID(ctx, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+
+	subscriptions, err := s.repo.Subscriptions(ctx, ownerID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetTimeline",
+		}).Error("Failed to get timeline subscriptions from repository")
+		return nil, err
+	}
+
+	timeline := &models.Timeline{
+		UserID:   ownerID,
+		AsOf:     currentMonth(),
+		Services: []models.TimelineService{},
+	}
+	if len(subscriptions) == 0 {
+		return timeline, nil
+	}
+
+	// Бессрочные подписки рассматриваются до горизонта: текущего месяца или
+	// самой поздней известной даты, если она в будущем.
+	from, horizon := subscriptions[0].StartDate, timeline.AsOf
+	for _, sub := range subscriptions {
+		if sub.StartDate.Before(from) {
+			from = sub.StartDate
+		}
+		if sub.StartDate.After(horizon) {
+			horizon = sub.StartDate
+		}
+		if sub.EndDate != nil && sub.EndDate.After(horizon) {
+			horizon = *sub.EndDate
+		}
+	}
+
+	charges, err := s.repo.Charges(ctx, ownerID, from, horizon)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetTimeline",
+		}).Error("Failed to get timeline charges from repository")
+		return nil, err
+	}
+	charged := make(map[uuid.UUID]map[time.Time]float64)
+	for _, charge := range charges {
+		if charged[charge.SubscriptionID] == nil {
+			charged[charge.SubscriptionID] = make(map[time.Time]float64)
+		}
+		charged[charge.SubscriptionID][timelineMonth(charge.Month)] += charge.Amount
+	}
+
+	// Подписки уже упорядочены по сервису и дате начала. Сервис определяется
+	// по названию без учета регистра, как и при поиске дубликатов.
+	for start := 0; start < len(subscriptions); {
+		end := start + 1
+		for end < len(subscriptions) && strings.EqualFold(subscriptions[end].ServiceName, subscriptions[start].ServiceName) {
+			end++
+		}
+		service := buildTimelineService(subscriptions[start:end], charged, horizon)
+		timeline.DoubleCharged += service.DoubleCharged
+		timeline.Services = append(timeline.Services, service)
+		start = end
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id":        userID,
+		"services":       len(timeline.Services),
+		"double_charged": timeline.DoubleCharged,
+		"method":         "GetTimeline",
+	}).Info("Subscription timeline built successfully")
+
+	return timeline, nil
+}
+
+// buildTimelineService проходит месяцы от первой подписки сервиса до
+// горизонта и собирает пересечения (две и более действующих подписки) и
+// разрывы (ни одной) между периодами. Переплата за пересечение — сумма
+// списаний всех его подписок за эти месяцы за вычетом самой большой из них.
+func buildTimelineService(subscriptions []models.TimelineSubscription, charged map[uuid.UUID]map[time.Time]float64, horizon time.Time) models.TimelineService {
+	service := models.TimelineService{
+		ServiceName: subscriptions[0].ServiceName,
+		Intervals:   make([]models.TimelineInterval, 0, len(subscriptions)),
+		Overlaps:    []models.TimelineOverlap{},
+		Gaps:        []models.TimelineGap{},
+	}
+
+	last := subscriptions[0].StartDate
+	openEnded := false
+	for _, sub := range subscriptions {
+		if service.ServiceID == nil {
+			service.ServiceID = sub.ServiceID
+		}
+		service.Intervals = append(service.Intervals, models.TimelineInterval{
+			SubscriptionID:  sub.ID,
+			StartDate:       sub.StartDate,
+			EndDate:         sub.EndDate,
+			Price:           sub.Price,
+			BillingInterval: sub.BillingInterval,
+		})
+		if sub.EndDate == nil {
+			openEnded = true
+		} else if sub.EndDate.After(last) {
+			last = *sub.EndDate
+		}
+	}
+	if openEnded {
+		last = horizon
+	}
+	last = timelineMonth(last)
+
+	var overlap *models.TimelineOverlap
+	var overlapCharges map[uuid.UUID]float64
+	var gap *models.TimelineGap
+
+	// closeOverlap завершает пересечение месяцем перед month; ongoing —
+	// пересечение продолжается за горизонтом.
+	closeOverlap := func(month time.Time, ongoing bool) {
+		if overlap == nil {
+			return
+		}
+		if !ongoing {
+			end := month.AddDate(0, -1, 0)
+			overlap.EndDate = &end
+		}
+		var total, largest float64
+		for _, amount := range overlapCharges {
+			total += amount
+			largest = math.Max(largest, amount)
+		}
+		overlap.DoubleCharged = int(math.Round(total - largest))
+		service.DoubleCharged += overlap.DoubleCharged
+		service.Overlaps = append(service.Overlaps, *overlap)
+		overlap = nil
+	}
+
+	var active []models.TimelineSubscription
+	for month := timelineMonth(subscriptions[0].StartDate); !month.After(last); month = month.AddDate(0, 1, 0) {
+		active = active[:0]
+		for _, sub := range subscriptions {
+			if !sub.StartDate.After(month) && (sub.EndDate == nil || !sub.EndDate.Before(month)) {
+				active = append(active, sub)
+			}
+		}
+
+		if len(active) == 0 {
+			if gap == nil {
+				gap = &models.TimelineGap{StartDate: month}
+			}
+			gap.EndDate = month
+			gap.Months++
+		} else if gap != nil {
+			service.Gaps = append(service.Gaps, *gap)
+			gap = nil
+		}
+
+		if len(active) < 2 {
+			closeOverlap(month, false)
+			continue
+		}
+		if overlap == nil {
+			overlap = &models.TimelineOverlap{StartDate: month}
+			overlapCharges = make(map[uuid.UUID]float64)
+		}
+		overlap.Months++
+		for _, sub := range active {
+			if !containsUUID(overlap.SubscriptionIDs, sub.ID) {
+				overlap.SubscriptionIDs = append(overlap.SubscriptionIDs, sub.ID)
+			}
+			overlapCharges[sub.ID] += charged[sub.ID][month]
+		}
+	}
+	openActive := 0
+	for _, sub := range active {
+		if sub.EndDate == nil {
+			openActive++
+		}
+	}
+	closeOverlap(last.AddDate(0, 1, 0), openActive > 1)
+
+	return service
+}
+
+// timelineMonth приводит дату к первому числу месяца в UTC, чтобы месяцы из
+// разных запросов совпадали как ключи.
+func timelineMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
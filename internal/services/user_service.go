@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"subscribe_project/internal/models"
+	"subscribe_project/internal/repository"
+	"subscribe_project/internal/tracing"
+	"subscribe_project/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultTimezone = "UTC"
+
+type UserService interface {
+	CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.User, error)
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	UpdateUser(ctx context.Context, id string, req models.UpdateUserRequest) error
+	// DeleteUser по умолчанию мягко удаляет пользователя и завершает его
+	// подписки; при hard == true удаляет пользователя вместе с подписками.
+	DeleteUser(ctx context.Context, id string, hard bool) error
+	ListUsers(ctx context.Context, page, limit int) ([]models.User, error)
+}
+
+type userService struct {
+	repo        repository.UserRepository
+	invalidator CacheInvalidator
+}
+
+func NewUserService(repo repository.UserRepository, invalidator CacheInvalidator) UserService {
+	logger.Log.WithField("component", "user_service").Info("Creating new user service")
+	return &userService{repo: repo, invalidator: invalidator}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req models.CreateUserRequest) (result *models.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.CreateUser")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"method":    "CreateUser",
+		"has_email": req.Email != nil,
+	}).Info("Creating user")
+
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	user := &models.User{
+		Name:        name,
+		Timezone:    defaultTimezone,
+		Preferences: types.JSONText("{}"),
+	}
+
+	if req.Email != nil && *req.Email != "" {
+		email, err := normalizeEmail(*req.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Email = &email
+	}
+
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			return nil, err
+		}
+		user.Timezone = *req.Timezone
+	}
+
+	if len(req.Preferences) > 0 {
+		if err := validatePreferences(req.Preferences); err != nil {
+			return nil, err
+		}
+		user.Preferences = types.JSONText(req.Preferences)
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "CreateUser",
+		}).Error("Failed to create user in repository")
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: email already in use", ErrConflict)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"user_id": user.ID.String(),
+		"method":  "CreateUser",
+	}).Info("User created successfully")
+
+	return user, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, id string) (result *models.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.GetUser")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "GetUser",
+		}).Warn("Failed to get user from repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id string, req models.UpdateUserRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.UpdateUser")
+	defer func() { tracing.End(span, err) }()
+
+	logger.FromContext(ctx).WithField("method", "UpdateUser").Info("Updating user")
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidInput)
+		}
+		req.Name = &name
+	}
+
+	if req.Email != nil && *req.Email != "" {
+		email, err := normalizeEmail(*req.Email)
+		if err != nil {
+			return err
+		}
+		req.Email = &email
+	}
+
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Preferences) > 0 {
+		if err := validatePreferences(req.Preferences); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Update(ctx, userID, &req); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "UpdateUser",
+		}).Error("Failed to update user in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user %s", ErrNotFound, id)
+		}
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return fmt.Errorf("%w: email already in use", ErrConflict)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithField("method", "UpdateUser").Info("User updated successfully")
+
+	return nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id string, hard bool) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.DeleteUser")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id", ErrInvalidInput)
+	}
+
+	var ended int64
+	if hard {
+		err = s.repo.Delete(ctx, userID)
+	} else {
+		ended, err = s.repo.SoftDelete(ctx, userID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"hard":   hard,
+			"method": "DeleteUser",
+		}).Error("Failed to delete user in repository")
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user %s", ErrNotFound, id)
+		}
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"hard":                hard,
+		"ended_subscriptions": ended,
+		"method":              "DeleteUser",
+	}).Info("User deleted successfully")
+
+	// Удаление меняет подписки пользователя и доли в чужих подписках.
+	s.invalidator.InvalidateAll(ctx)
+	return nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, page, limit int) (result []models.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.ListUsers")
+	defer func() { tracing.End(span, err) }()
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	users, err := s.repo.List(ctx, limit, (page-1)*limit)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"error":  err.Error(),
+			"method": "ListUsers",
+		}).Error("Failed to list users from repository")
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Name != "" {
+		return "", fmt.Errorf("%w: invalid email", ErrInvalidInput)
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+func validateTimezone(timezone string) error {
+	if timezone == "" {
+		return fmt.Errorf("%w: timezone must not be empty", ErrInvalidInput)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, timezone)
+	}
+	return nil
+}
+
+// validatePreferences допускает только JSON-объект, чтобы настройки можно было
+// дополнять по ключам.
+func validatePreferences(preferences json.RawMessage) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal(preferences, &object); err != nil || object == nil {
+		return fmt.Errorf("%w: preferences must be a JSON object", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey int
+
+const (
+	entryContextKey contextKey = iota
+	requestIDContextKey
+)
+
+// WithEntry сохраняет в контексте логгер, привязанный к запросу.
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryContextKey, entry)
+}
+
+// FromContext возвращает логгер запроса из контекста или глобальный логгер,
+// если контекст не связан с запросом. Контекст привязывается к записи, чтобы
+// хуки могли достать из него идентификаторы трассировки.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx == nil {
+		return logrus.NewEntry(Log)
+	}
+	if entry, ok := ctx.Value(entryContextKey).(*logrus.Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return Log.WithContext(ctx)
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redactedValue = "[REDACTED]"
+
+// defaultFieldsHook добавляет поля сервиса в каждую запись, не перезаписывая
+// значения, заданные явно.
+type defaultFieldsHook struct {
+	fields logrus.Fields
+}
+
+func newDefaultFieldsHook(fields logrus.Fields) *defaultFieldsHook {
+	return &defaultFieldsHook{fields: fields}
+}
+
+func (h *defaultFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *defaultFieldsHook) Fire(entry *logrus.Entry) error {
+	for key, value := range h.fields {
+		if _, exists := entry.Data[key]; !exists {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
+
+// traceHook добавляет trace_id и span_id активного спана из контекста записи.
+type traceHook struct{}
+
+func (h *traceHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *traceHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	entry.Data["trace_id"] = spanContext.TraceID().String()
+	entry.Data["span_id"] = spanContext.SpanID().String()
+	return nil
+}
+
+// redactHook скрывает значения чувствительных полей. Имена полей
+// сравниваются без учета регистра.
+type redactHook struct {
+	fields map[string]struct{}
+}
+
+func newRedactHook(fields []string) *redactHook {
+	hook := &redactHook{fields: make(map[string]struct{}, len(fields))}
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field != "" {
+			hook.fields[field] = struct{}{}
+		}
+	}
+	return hook
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	for key := range entry.Data {
+		if _, sensitive := h.fields[strings.ToLower(key)]; sensitive {
+			entry.Data[key] = redactedValue
+		}
+	}
+	return nil
+}
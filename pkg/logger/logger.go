@@ -1,29 +1,53 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Log *logrus.Logger
 
+const defaultRedactFields = "user_id,ip,authorization"
+
 func InitLogger(serviceName string) {
 	Log = logrus.New()
 
-	Log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-		ForceColors:     true,
-	})
+	format := strings.ToLower(getEnv("LOG_FORMAT", "text"))
+	logFile := os.Getenv("LOG_FILE")
 
-	Log.SetOutput(os.Stdout)
+	switch format {
+	case "json":
+		Log.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+		})
+	default:
+		Log.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+			// Цветной вывод оставляем только для консоли, иначе в файл попадут escape-последовательности.
+			ForceColors: logFile == "",
+		})
+	}
 
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
+	if logFile != "" {
+		Log.SetOutput(io.MultiWriter(os.Stdout, &lumberjack.Logger{
+			Filename:   logFile,
+			MaxSize:    getIntEnv("LOG_MAX_SIZE_MB", 100),
+			MaxAge:     getIntEnv("LOG_MAX_AGE_DAYS", 14),
+			MaxBackups: getIntEnv("LOG_MAX_BACKUPS", 7),
+			Compress:   getEnv("LOG_COMPRESS", "true") == "true",
+		}))
+	} else {
+		Log.SetOutput(os.Stdout)
 	}
 
+	logLevel := getEnv("LOG_LEVEL", "info")
+
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
 		level = logrus.InfoLevel
@@ -32,10 +56,32 @@ func InitLogger(serviceName string) {
 
 	Log.SetLevel(level)
 
-	Log.WithField("service", serviceName)
+	Log.AddHook(newDefaultFieldsHook(logrus.Fields{
+		"service": serviceName,
+		"version": getEnv("SERVICE_VERSION", "dev"),
+		"env":     getEnv("APP_ENV", "development"),
+	}))
+	Log.AddHook(&traceHook{})
+	Log.AddHook(newRedactHook(strings.Split(getEnv("LOG_REDACT_FIELDS", defaultRedactFields), ",")))
 
 	Log.WithFields(logrus.Fields{
-		"level":   level.String(),
-		"service": serviceName,
+		"level":    level.String(),
+		"format":   format,
+		"log_file": logFile,
 	}).Info("Logger initialized")
 }
+
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}